@@ -1,11 +1,10 @@
 package main
 
 import (
-	"fmt"
+	"time"
+
 	"github.com/heartbytenet/go-lerpc/pkg/proto"
 	"github.com/heartbytenet/go-lerpc/pkg/server"
-	"math/rand"
-	"time"
 )
 
 func main() {
@@ -16,7 +15,12 @@ func main() {
 		"file.prepare",
 		server.AuthNone(),
 		func(ctx *server.RequestContext, request proto.Request) (result proto.Result) {
-			key := fmt.Sprintf("%x", rand.Int())
+			key, err := server.NewDownloadKey()
+			if err != nil {
+				return proto.NewResult().
+					WithCode(proto.ResultCodeError).
+					WithMessage(err.Error())
+			}
 
 			ctx.GetExecutor().AddDownloadHandler(server.NewDownloadHandlerFile(
 				key,
@@ -25,9 +29,12 @@ func main() {
 				"image/png",
 				"./download.png"))
 
+			query := ctx.GetExecutor().GetSigner().Sign(key, time.Second*5, request.GetToken())
+
 			return proto.NewResult().
 				WithCode(proto.ResultCodeSuccess).
-				SetData("key", key)
+				SetData("key", key).
+				SetData("url", "/download?"+query.Encode())
 		}))
 
 	if err := s.Run(); err != nil {
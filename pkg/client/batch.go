@@ -0,0 +1,228 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/heartbytenet/go-lerpc/pkg/proto"
+)
+
+func (client *Client) ExecuteBatch(requests []proto.Request) (promises []*proto.Promise[proto.Result], err error) {
+	requests = client.InterceptAll(requests)
+
+	switch client.GetMode() {
+	case ClientModeHttp, ClientModeHttps:
+		promises = client.ExecuteBatchHttp(client.GetMode(), requests)
+		return
+
+	case ClientModeWs, ClientModeWss:
+		return client.ExecuteBatchWs(requests)
+
+	default:
+		panic("invalid client mode")
+	}
+}
+
+func (client *Client) ExecuteBatchWs(requests []proto.Request) (promises []*proto.Promise[proto.Result], err error) {
+	var (
+		promise *proto.Promise[proto.Result]
+	)
+
+	promises = make([]*proto.Promise[proto.Result], 0, len(requests))
+
+	for _, request := range requests {
+		promise, err = client.ExecuteWs(request)
+		if err != nil {
+			return
+		}
+
+		promises = append(promises, promise)
+	}
+
+	return
+}
+
+func (client *Client) ExecuteBatchHttp(mode ClientMode, requests []proto.Request) (promises []*proto.Promise[proto.Result]) {
+	requests = append([]proto.Request(nil), requests...)
+	promises = make([]*proto.Promise[proto.Result], len(requests))
+
+	for index, request := range requests {
+		requests[index] = client.PrepareRequest(request)
+		promises[index] = proto.NewPromise[proto.Result]()
+	}
+
+	go client.SendBatchHttp(mode, requests, promises)
+
+	return
+}
+
+func (client *Client) PrepareRequest(request proto.Request) proto.Request {
+	if request.GetToken() == "" {
+		request = request.WithToken(client.token)
+	}
+
+	if request.GetKey() == "" {
+		request = request.WithKey(client.NextKey())
+	}
+
+	return request
+}
+
+func (client *Client) SendBatchHttp(mode ClientMode, requests []proto.Request, promises []*proto.Promise[proto.Result]) {
+	var (
+		pending  map[string]*proto.Promise[proto.Result]
+		keys     map[string]struct{}
+		accepted []proto.Request
+		waiting  []*proto.Promise[proto.Result]
+		results  []proto.Result
+		req      *http.Request
+		res      *http.Response
+		err      error
+	)
+
+	pending = make(map[string]*proto.Promise[proto.Result], len(requests))
+	keys = make(map[string]struct{}, len(requests))
+
+	accepted = make([]proto.Request, 0, len(requests))
+	waiting = make([]*proto.Promise[proto.Result], 0, len(promises))
+
+	for index, request := range requests {
+		if _, flag := keys[request.GetKey()]; flag {
+			promises[index].Failed(fmt.Errorf("%w: %s", proto.ErrKeyInFlight, request.GetKey()))
+			continue
+		}
+
+		keys[request.GetKey()] = struct{}{}
+		accepted = append(accepted, request)
+		waiting = append(waiting, promises[index])
+
+		if request.GetNoReply() {
+			continue
+		}
+
+		pending[request.GetKey()] = promises[index]
+	}
+
+	requests, promises = accepted, waiting
+	if len(requests) < 1 {
+		return
+	}
+
+	fail := func(err error) {
+		for _, promise := range promises {
+			promise.Failed(err)
+		}
+	}
+
+	req, err = client.NewRequestHttp(mode, requests)
+	if err != nil {
+		fail(err)
+		return
+	}
+
+	res, err = client.httpClient.Do(req)
+	if err != nil {
+		fail(err)
+		return
+	}
+	defer res.Body.Close()
+
+	err = client.DecodeResponse(res, &results)
+	if err != nil {
+		fail(err)
+		return
+	}
+
+	for index, request := range requests {
+		if request.GetNoReply() {
+			promises[index].Complete(proto.NewResult().
+				WithKey(request.GetKey()).
+				WithCode(proto.ResultCodeSuccess))
+		}
+	}
+
+	for _, result := range results {
+		promise, flag := pending[result.GetKey()]
+		if !flag {
+			continue
+		}
+
+		promise.Complete(result)
+		delete(pending, result.GetKey())
+	}
+
+	for key, promise := range pending {
+		promise.Failed(fmt.Errorf("batch response is missing key %s", key))
+	}
+}
+
+type Batcher struct {
+	client *Client
+	mode   ClientMode
+	window time.Duration
+	size   int
+
+	mutex    sync.Mutex
+	requests []proto.Request
+	promises []*proto.Promise[proto.Result]
+	timer    *time.Timer
+}
+
+func NewBatcher(client *Client, mode ClientMode, window time.Duration, size int) *Batcher {
+	return &Batcher{
+		client: client,
+		mode:   mode,
+		window: window,
+		size:   size,
+	}
+}
+
+func (batcher *Batcher) Push(request proto.Request) (promise *proto.Promise[proto.Result]) {
+	promise = proto.NewPromise[proto.Result]()
+
+	batcher.mutex.Lock()
+
+	batcher.requests = append(batcher.requests, batcher.client.PrepareRequest(request))
+	batcher.promises = append(batcher.promises, promise)
+
+	if batcher.size > 0 && len(batcher.requests) >= batcher.size {
+		requests, promises := batcher.take()
+		batcher.mutex.Unlock()
+
+		go batcher.client.SendBatchHttp(batcher.mode, requests, promises)
+		return
+	}
+
+	if batcher.timer == nil {
+		batcher.timer = time.AfterFunc(batcher.window, batcher.Flush)
+	}
+
+	batcher.mutex.Unlock()
+	return
+}
+
+func (batcher *Batcher) Flush() {
+	batcher.mutex.Lock()
+	requests, promises := batcher.take()
+	batcher.mutex.Unlock()
+
+	if len(requests) < 1 {
+		return
+	}
+
+	batcher.client.SendBatchHttp(batcher.mode, requests, promises)
+}
+
+func (batcher *Batcher) take() (requests []proto.Request, promises []*proto.Promise[proto.Result]) {
+	requests, promises = batcher.requests, batcher.promises
+	batcher.requests, batcher.promises = nil, nil
+
+	if batcher.timer != nil {
+		batcher.timer.Stop()
+		batcher.timer = nil
+	}
+
+	return
+}
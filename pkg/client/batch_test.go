@@ -0,0 +1,57 @@
+package client_test
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/heartbytenet/go-lerpc/pkg/client"
+	"github.com/heartbytenet/go-lerpc/pkg/proto"
+	"github.com/heartbytenet/go-lerpc/pkg/server"
+)
+
+func TestExecuteBatchDuplicateKey(t *testing.T) {
+	instance := server.NewServer()
+	instance.AddHandler(server.NewHandlerWith("test", "echo", nil,
+		func(ctx *server.RequestContext, request proto.Request) proto.Result {
+			return proto.NewResult().
+				WithCode(proto.ResultCodeSuccess).
+				SetData("i", request.GetParam("i").GetDefault(nil))
+		}))
+	instance.Routes()
+
+	if err := instance.GetExecutor().Start(time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+
+	test := httptest.NewServer(instance.GetEngine())
+	t.Cleanup(test.Close)
+
+	remote := client.NewClient(client.ClientModeHttp, strings.TrimPrefix(test.URL, "http://"), "")
+
+	promises, err := remote.ExecuteBatch([]proto.Request{
+		proto.NewRequest().WithKey("a").WithNamespace("test").WithMethod("echo").SetParam("i", 1),
+		proto.NewRequest().WithKey("a").WithNamespace("test").WithMethod("echo").SetParam("i", 2),
+		proto.NewRequest().WithKey("b").WithNamespace("test").WithMethod("echo").SetParam("i", 3),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := promises[0].Await()
+	if err != nil || result.GetDataAll()["i"] != float64(1) {
+		t.Fatalf("first request: %v, %v", result, err)
+	}
+
+	_, err = promises[1].Await()
+	if !errors.Is(err, proto.ErrKeyInFlight) {
+		t.Fatalf("expected duplicate key to fail, got %v", err)
+	}
+
+	result, err = promises[2].Await()
+	if err != nil || result.GetDataAll()["i"] != float64(3) {
+		t.Fatalf("third request: %v, %v", result, err)
+	}
+}
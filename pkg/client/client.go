@@ -1,11 +1,16 @@
 package client
 
 import (
-	"bytes"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
-	"github.com/goccy/go-json"
-	"github.com/heartbytenet/go-lerpc/pkg/proto"
 	"net/http"
+	"sync/atomic"
+
+	"github.com/gorilla/websocket"
+	"github.com/heartbytenet/bblib/containers/optionals"
+	"github.com/heartbytenet/bblib/containers/sync"
+	"github.com/heartbytenet/go-lerpc/pkg/proto"
 )
 
 type ClientMode int
@@ -18,21 +23,69 @@ const (
 )
 
 type Client struct {
-	mode   ClientMode
-	remote string
-	token  string
+	mode     ClientMode
+	remote   string
+	token    string
+	settings Settings
 
 	httpClient *http.Client
+
+	conn         *sync.Locked[*websocket.Conn]
+	codec        *sync.Locked[proto.Codec]
+	pending      *sync.Locked[map[string]*proto.Promise[proto.Result]]
+	transfers    *sync.Locked[map[string]*Transfer]
+	streams      *sync.Locked[map[string]*Stream]
+	notifies     *sync.Locked[map[string][]NotifyFunction]
+	interceptors *sync.Locked[[]InterceptorFunction]
+	batcher      *Batcher
+	counter      atomic.Uint64
+	keyPrefix    string
+	version      atomic.Uint32
+	capabilities *sync.Locked[optionals.Optional[proto.Capabilities]]
+
+	lastActivity atomic.Int64
 }
 
 func NewClient(mode ClientMode, remote string, token string) *Client {
-	return &Client{
-		mode:   mode,
-		remote: remote,
-		token:  token,
+	return NewClientWithSettings(mode, remote, token, NewSettingsDefault())
+}
+
+func NewClientWithSettings(mode ClientMode, remote string, token string, settings Settings) (client *Client) {
+	client = &Client{
+		mode:     mode,
+		remote:   remote,
+		token:    token,
+		settings: settings,
 
 		httpClient: &http.Client{},
+		keyPrefix:  NewKeyPrefix(),
+
+		conn:         sync.NewLocked[*websocket.Conn](nil),
+		codec:        sync.NewLocked(settings.GetCodec()),
+		pending:      sync.NewLocked(map[string]*proto.Promise[proto.Result]{}),
+		transfers:    sync.NewLocked(map[string]*Transfer{}),
+		streams:      sync.NewLocked(map[string]*Stream{}),
+		notifies:     sync.NewLocked(map[string][]NotifyFunction{}),
+		interceptors: sync.NewLocked([]InterceptorFunction{}),
+		capabilities: sync.NewLocked(optionals.None[proto.Capabilities]()),
+	}
+
+	if settings.BatchWindow > 0 && (mode == ClientModeHttp || mode == ClientModeHttps) {
+		client.batcher = NewBatcher(client, mode, settings.BatchWindow, settings.BatchSize)
 	}
+
+	return
+}
+
+func NewKeyPrefix() string {
+	data := make([]byte, 6)
+
+	_, err := rand.Read(data)
+	if err != nil {
+		panic(err)
+	}
+
+	return hex.EncodeToString(data) + "-"
 }
 
 func (client *Client) GetMode() ClientMode {
@@ -47,6 +100,10 @@ func (client *Client) GetToken() string {
 	return client.token
 }
 
+func (client *Client) GetSettings() Settings {
+	return client.settings
+}
+
 func (client *Client) GetUrl(mode ClientMode) string {
 	switch mode {
 	case ClientModeHttp:
@@ -67,26 +124,93 @@ func (client *Client) GetUrl(mode ClientMode) string {
 }
 
 func (client *Client) Open() (err error) {
-	// Todo: open websocket connection
+	switch client.GetMode() {
+	case ClientModeWs, ClientModeWss:
+		return client.OpenWs()
 
-	return
+	default:
+		return
+	}
 }
 
 func (client *Client) Execute(request proto.Request) (promise *proto.Promise[proto.Result], err error) {
 	mode := client.GetMode()
+	request = client.Intercept(request)
 
 	switch mode {
 	case ClientModeHttp, ClientModeHttps:
 		{
+			if client.batcher != nil {
+				promise = client.batcher.Push(request)
+				return
+			}
+
 			promise = client.ExecuteHttp(mode, request)
 			return
 		}
 
+	case ClientModeWs, ClientModeWss:
+		{
+			promise, err = client.ExecuteWs(request)
+			return
+		}
+
 	default:
 		panic("not implemented")
 	}
 }
 
+func (client *Client) Notify(request proto.Request) (err error) {
+	request = client.PrepareRequest(client.Intercept(request).WithNoReply(true))
+
+	switch client.GetMode() {
+	case ClientModeHttp, ClientModeHttps:
+		return client.NotifyHttp(client.GetMode(), request)
+
+	case ClientModeWs, ClientModeWss:
+		return client.WriteValue(request)
+
+	default:
+		panic("not implemented")
+	}
+}
+
+func (client *Client) NotifyHttp(mode ClientMode, request proto.Request) (err error) {
+	var (
+		result proto.Result
+		req    *http.Request
+		res    *http.Response
+	)
+
+	req, err = client.NewRequestHttp(mode, request)
+	if err != nil {
+		return
+	}
+
+	res, err = client.httpClient.Do(req)
+	if err != nil {
+		return
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == 202 {
+		return
+	}
+
+	err = client.DecodeResponse(res, &result)
+	if err != nil {
+		return fmt.Errorf("notify request failed: %s", res.Status)
+	}
+
+	return result.Check()
+}
+
+func (client *Client) Flush() {
+	if client.batcher != nil {
+		client.batcher.Flush()
+	}
+}
+
 func (client *Client) ExecuteSync(request proto.Request) (result proto.Result, err error) {
 	var (
 		promise *proto.Promise[proto.Result]
@@ -107,30 +231,17 @@ func (client *Client) ExecuteSync(request proto.Request) (result proto.Result, e
 
 func (client *Client) ExecuteHttp(mode ClientMode, request proto.Request) (promise *proto.Promise[proto.Result]) {
 	promise = proto.NewPromise[proto.Result]()
-
-	if request.GetToken() == "" {
-		request = request.WithToken(client.token)
-	}
+	request = client.PrepareRequest(request)
 
 	go func(request proto.Request) {
 		var (
 			result proto.Result
 			req    *http.Request
 			res    *http.Response
-			data   []byte
 			err    error
 		)
 
-		data, err = json.Marshal(request)
-		if err != nil {
-			promise.Failed(err)
-			return
-		}
-
-		req, err = http.NewRequest(
-			"POST",
-			client.GetUrl(mode),
-			bytes.NewReader(data))
+		req, err = client.NewRequestHttp(mode, request)
 		if err != nil {
 			promise.Failed(err)
 			return
@@ -148,12 +259,16 @@ func (client *Client) ExecuteHttp(mode ClientMode, request proto.Request) (promi
 			return
 		}
 
-		err = json.NewDecoder(res.Body).Decode(&result)
+		err = client.DecodeResponse(res, &result)
 		if err != nil {
 			promise.Failed(err)
 			return
 		}
 
+		if result.GetKey() == "" {
+			result = result.WithKey(request.GetKey())
+		}
+
 		promise.Complete(result)
 		return
 	}(request)
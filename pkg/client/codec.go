@@ -0,0 +1,99 @@
+package client
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/heartbytenet/go-lerpc/pkg/proto"
+)
+
+func (client *Client) GetCodec() proto.Codec {
+	return client.codec.Get()
+}
+
+func (client *Client) GetSubprotocols() []string {
+	preferred := client.settings.GetCodec().GetSubprotocol()
+
+	return append([]string{preferred}, slices.DeleteFunc(slices.Clone(client.settings.Subprotocols), func(value string) bool {
+		return value == preferred
+	})...)
+}
+
+func (client *Client) AcceptsEncoding(encoding string) (flag bool) {
+	if encoding == "" || encoding == proto.EncodingIdentity {
+		return false
+	}
+
+	flag = true
+	client.GetCapabilities().IfPresent(func(capabilities proto.Capabilities) {
+		flag = capabilities.HasEncoding(encoding)
+	})
+
+	return
+}
+
+func (client *Client) NewRequestHttp(mode ClientMode, value any) (req *http.Request, err error) {
+	var (
+		codec    proto.Codec
+		data     []byte
+		encoding string
+	)
+
+	codec = client.GetCodec()
+
+	data, err = codec.Marshal(value)
+	if err != nil {
+		return
+	}
+
+	if len(data) >= client.settings.CompressionMinSize && client.AcceptsEncoding(client.settings.CompressionEncoding) {
+		encoding = client.settings.CompressionEncoding
+
+		data, err = proto.Compress(encoding, data)
+		if err != nil {
+			return
+		}
+	}
+
+	req, err = http.NewRequest(
+		"POST",
+		client.GetUrl(mode),
+		bytes.NewReader(data))
+	if err != nil {
+		return
+	}
+
+	req.Header.Set("Content-Type", codec.GetContentType())
+	req.Header.Set("Accept", codec.GetContentType())
+	req.Header.Set("Accept-Encoding", strings.Join(proto.Encodings, ", "))
+	req.Header.Set(proto.HeaderVersion, proto.VersionRange())
+
+	if encoding != "" && encoding != proto.EncodingIdentity {
+		req.Header.Set("Content-Encoding", encoding)
+	}
+
+	return
+}
+
+func (client *Client) DecodeResponse(res *http.Response, value any) (err error) {
+	var (
+		data []byte
+	)
+
+	data, err = io.ReadAll(res.Body)
+	if err != nil {
+		return
+	}
+
+	data, err = proto.Decompress(res.Header.Get("Content-Encoding"), data, client.settings.MaxMessageSize)
+	if err != nil {
+		return
+	}
+
+	return proto.GetCodecByContentType(res.Header.Get("Content-Type")).
+		GetDefault(proto.CodecDefault).
+		Unmarshal(data, value)
+}
@@ -0,0 +1,33 @@
+package client_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/heartbytenet/go-lerpc/pkg/client"
+	"github.com/heartbytenet/go-lerpc/pkg/proto"
+)
+
+func TestNewRequestHttpCompression(t *testing.T) {
+	instance := client.NewClient(client.ClientModeHttp, "localhost:0", "")
+	minSize := instance.GetSettings().CompressionMinSize
+
+	req, err := instance.NewRequestHttp(client.ClientModeHttp, proto.NewRequest().
+		SetParam("value", strings.Repeat("x", minSize)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if encoding := req.Header.Get("Content-Encoding"); encoding != proto.EncodingGzip {
+		t.Fatalf("large request sent with encoding %q", encoding)
+	}
+
+	req, err = instance.NewRequestHttp(client.ClientModeHttp, proto.NewRequest())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if encoding := req.Header.Get("Content-Encoding"); encoding != "" {
+		t.Fatalf("small request sent with encoding %q", encoding)
+	}
+}
@@ -0,0 +1,42 @@
+package client
+
+import (
+	"github.com/heartbytenet/go-lerpc/pkg/proto"
+)
+
+type InterceptorFunction func(request proto.Request) proto.Request
+
+func (client *Client) AddInterceptor(fn InterceptorFunction) {
+	client.interceptors.Map(func(data []InterceptorFunction) []InterceptorFunction {
+		return append(data, fn)
+	})
+}
+
+func (client *Client) Intercept(request proto.Request) proto.Request {
+	var (
+		interceptors []InterceptorFunction
+	)
+
+	client.interceptors.Apply(func(data []InterceptorFunction) {
+		interceptors = data
+	})
+
+	if request.GetVersion() == 0 && client.GetVersion() > 0 {
+		request = request.WithVersion(client.GetVersion())
+	}
+
+	for _, fn := range interceptors {
+		request = fn(request)
+	}
+
+	return request
+}
+
+func (client *Client) InterceptAll(requests []proto.Request) []proto.Request {
+	result := make([]proto.Request, len(requests))
+	for index, request := range requests {
+		result[index] = client.Intercept(request)
+	}
+
+	return result
+}
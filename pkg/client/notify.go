@@ -0,0 +1,46 @@
+package client
+
+import (
+	"github.com/heartbytenet/go-lerpc/pkg/proto"
+)
+
+type NotifyFunction func(topic string, data map[string]any)
+
+func (client *Client) OnNotify(topic string, callback NotifyFunction) {
+	client.notifies.Map(func(data map[string][]NotifyFunction) map[string][]NotifyFunction {
+		data[topic] = append(data[topic], callback)
+		return data
+	})
+}
+
+func (client *Client) HandleNotify(topic string, data map[string]any) {
+	var (
+		callbacks []NotifyFunction
+	)
+
+	client.notifies.Apply(func(value map[string][]NotifyFunction) {
+		for pattern, entries := range value {
+			if !proto.MatchTopic(pattern, topic) {
+				continue
+			}
+
+			callbacks = append(callbacks, entries...)
+		}
+	})
+
+	for _, callback := range callbacks {
+		callback(topic, data)
+	}
+}
+
+func (client *Client) Subscribe(pattern string) (err error) {
+	return client.ExecuteFrame(proto.NewFrame(proto.FrameKindSubscribe).
+		WithToken(client.token).
+		WithTopic(pattern))
+}
+
+func (client *Client) Unsubscribe(pattern string) (err error) {
+	return client.ExecuteFrame(proto.NewFrame(proto.FrameKindUnsubscribe).
+		WithToken(client.token).
+		WithTopic(pattern))
+}
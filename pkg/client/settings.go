@@ -0,0 +1,61 @@
+package client
+
+import (
+	"time"
+
+	"github.com/heartbytenet/go-lerpc/pkg/proto"
+)
+
+type Settings struct {
+	PingInterval   time.Duration
+	PongTimeout    time.Duration
+	WriteTimeout   time.Duration
+	IdleTimeout    time.Duration
+	MaxMessageSize int64
+
+	Origin            string
+	Subprotocols      []string
+	EnableCompression bool
+	ReadBufferSize    int
+	WriteBufferSize   int
+
+	BatchWindow time.Duration
+	BatchSize   int
+
+	Codec proto.Codec
+
+	CompressionEncoding string
+	CompressionMinSize  int
+}
+
+func NewSettingsDefault() Settings {
+	return Settings{
+		PingInterval:   time.Second * 30,
+		PongTimeout:    time.Second * 60,
+		WriteTimeout:   time.Second * 10,
+		IdleTimeout:    0,
+		MaxMessageSize: 4 << 20,
+
+		Origin:            "",
+		Subprotocols:      []string{proto.Subprotocol},
+		EnableCompression: false,
+		ReadBufferSize:    4096,
+		WriteBufferSize:   4096,
+
+		BatchWindow: 0,
+		BatchSize:   64,
+
+		Codec: proto.CodecDefault,
+
+		CompressionEncoding: proto.EncodingGzip,
+		CompressionMinSize:  1024,
+	}
+}
+
+func (settings Settings) GetCodec() proto.Codec {
+	if settings.Codec == nil {
+		return proto.CodecDefault
+	}
+
+	return settings.Codec
+}
@@ -0,0 +1,371 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/goccy/go-json"
+	"github.com/heartbytenet/bblib/containers/optionals"
+	"github.com/heartbytenet/go-lerpc/pkg/proto"
+)
+
+var (
+	ErrStreamUnsupported = errors.New("operation not supported on http streams")
+)
+
+type Stream struct {
+	client *Client
+	key    string
+	body   io.ReadCloser
+	reader *bufio.Reader
+
+	mutex   sync.Mutex
+	signal  chan struct{}
+	credits chan struct{}
+	frames  []proto.Frame
+	value   map[string]any
+	done    bool
+	ended   bool
+	err     error
+	sent    uint32
+	limit   uint32
+}
+
+func NewStream(client *Client, key string) *Stream {
+	return &Stream{
+		client: client,
+		key:    key,
+
+		signal:  make(chan struct{}, 1),
+		credits: make(chan struct{}, 1),
+		frames:  make([]proto.Frame, 0),
+	}
+}
+
+func wake(signal chan struct{}) {
+	select {
+	case signal <- struct{}{}:
+	default:
+	}
+}
+
+func (stream *Stream) GetKey() string {
+	return stream.key
+}
+
+func (stream *Stream) Push(frame proto.Frame) {
+	stream.mutex.Lock()
+	stream.frames = append(stream.frames, frame)
+	if frame.GetKind() != proto.FrameKindStreamData {
+		stream.ended = true
+	}
+	stream.mutex.Unlock()
+
+	wake(stream.signal)
+	wake(stream.credits)
+}
+
+func (stream *Stream) Ack(limit uint32) {
+	stream.mutex.Lock()
+	if limit > stream.limit {
+		stream.limit = limit
+	}
+	stream.mutex.Unlock()
+
+	wake(stream.credits)
+}
+
+func (stream *Stream) acquire() (err error) {
+	for {
+		stream.mutex.Lock()
+		if stream.done || stream.ended {
+			err = stream.err
+			stream.mutex.Unlock()
+
+			wake(stream.credits)
+			if err == nil {
+				err = io.ErrClosedPipe
+			}
+			return
+		}
+
+		if stream.sent < stream.limit {
+			stream.sent++
+			if stream.sent < stream.limit {
+				wake(stream.credits)
+			}
+			stream.mutex.Unlock()
+			return
+		}
+		stream.mutex.Unlock()
+
+		<-stream.credits
+	}
+}
+
+func (stream *Stream) isDone() bool {
+	stream.mutex.Lock()
+	defer stream.mutex.Unlock()
+
+	return stream.done
+}
+
+func (stream *Stream) Next() bool {
+	var (
+		frame proto.Frame
+		err   error
+	)
+
+	for {
+		if stream.isDone() {
+			return false
+		}
+
+		frame, err = stream.nextFrame()
+		if err != nil {
+			stream.finish(err)
+			return false
+		}
+
+		switch frame.GetKind() {
+		case proto.FrameKindStreamData:
+			stream.mutex.Lock()
+			stream.value = frame.GetDataAll()
+			stream.mutex.Unlock()
+			return true
+
+		case proto.FrameKindStreamEnd:
+			stream.finish(nil)
+			return false
+
+		case proto.FrameKindStreamError:
+			stream.finish(fmt.Errorf("stream failed: %s", frame.GetMessage()))
+			return false
+		}
+	}
+}
+
+func (stream *Stream) nextFrame() (frame proto.Frame, err error) {
+	var (
+		line []byte
+	)
+
+	if stream.reader != nil {
+		line, err = stream.reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) && len(line) > 0 {
+			err = nil
+		}
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+			return
+		}
+		if err != nil {
+			return
+		}
+
+		err = json.Unmarshal(line, &frame)
+		return
+	}
+
+	for {
+		stream.mutex.Lock()
+		if len(stream.frames) > 0 {
+			frame = stream.frames[0]
+			stream.frames = stream.frames[1:]
+			stream.mutex.Unlock()
+			return
+		}
+		if stream.done {
+			err = stream.err
+			stream.mutex.Unlock()
+
+			if err == nil {
+				err = io.EOF
+			}
+			return
+		}
+		stream.mutex.Unlock()
+
+		<-stream.signal
+	}
+}
+
+func (stream *Stream) finish(err error) {
+	stream.mutex.Lock()
+	if stream.done {
+		stream.mutex.Unlock()
+		return
+	}
+
+	stream.done = true
+	stream.err = err
+	stream.value = nil
+	stream.mutex.Unlock()
+
+	if stream.body != nil {
+		_ = stream.body.Close()
+	}
+
+	stream.client.RemoveStream(stream.key)
+
+	wake(stream.signal)
+	wake(stream.credits)
+}
+
+func (stream *Stream) Value() map[string]any {
+	stream.mutex.Lock()
+	defer stream.mutex.Unlock()
+
+	return stream.value
+}
+
+func (stream *Stream) Err() error {
+	stream.mutex.Lock()
+	defer stream.mutex.Unlock()
+
+	return stream.err
+}
+
+func (stream *Stream) Send(data map[string]any) (err error) {
+	if stream.reader != nil {
+		return ErrStreamUnsupported
+	}
+
+	err = stream.acquire()
+	if err != nil {
+		return
+	}
+
+	return stream.client.WriteFrame(proto.NewFrame(proto.FrameKindStreamData).
+		WithKey(stream.key).
+		WithData(data))
+}
+
+func (stream *Stream) CloseSend() error {
+	if stream.reader != nil {
+		return nil
+	}
+
+	return stream.client.WriteFrame(proto.NewFrame(proto.FrameKindStreamEnd).
+		WithKey(stream.key))
+}
+
+func (stream *Stream) Close() (err error) {
+	if stream.isDone() {
+		return
+	}
+
+	if stream.reader == nil {
+		err = stream.client.WriteFrame(proto.NewFrame(proto.FrameKindCancel).
+			WithKey(stream.key))
+	}
+
+	stream.finish(proto.ErrCancelled)
+	return
+}
+
+func (client *Client) GetStream(key string) (result optionals.Optional[*Stream]) {
+	result = optionals.None[*Stream]()
+
+	client.streams.Apply(func(data map[string]*Stream) {
+		stream, flag := data[key]
+		if !flag {
+			return
+		}
+
+		result = optionals.Some(stream)
+	})
+
+	return
+}
+
+func (client *Client) RemoveStream(key string) {
+	client.streams.Map(func(data map[string]*Stream) map[string]*Stream {
+		delete(data, key)
+		return data
+	})
+}
+
+func (client *Client) Stream(request proto.Request) (stream *Stream, err error) {
+	request = client.PrepareRequest(client.Intercept(request).WithStream(true))
+
+	switch client.GetMode() {
+	case ClientModeWs, ClientModeWss:
+		return client.StreamWs(request)
+
+	default:
+		return client.StreamHttp(request)
+	}
+}
+
+func (client *Client) StreamWs(request proto.Request) (stream *Stream, err error) {
+	stream = NewStream(client, request.GetKey())
+
+	client.streams.Map(func(data map[string]*Stream) map[string]*Stream {
+		if _, flag := data[stream.GetKey()]; flag {
+			err = fmt.Errorf("%w: %s", proto.ErrKeyInFlight, stream.GetKey())
+			return data
+		}
+
+		data[stream.GetKey()] = stream
+		return data
+	})
+	if err != nil {
+		stream = nil
+		return
+	}
+
+	err = client.WriteValue(request)
+	if err != nil {
+		client.RemoveStream(stream.GetKey())
+		stream = nil
+		return
+	}
+
+	return
+}
+
+func (client *Client) StreamHttp(request proto.Request) (stream *Stream, err error) {
+	var (
+		req  *http.Request
+		res  *http.Response
+		data []byte
+	)
+
+	data, err = json.Marshal(request)
+	if err != nil {
+		return
+	}
+
+	req, err = http.NewRequest(
+		"POST",
+		client.GetUrl(client.GetMode()),
+		bytes.NewReader(data))
+	if err != nil {
+		return
+	}
+
+	req.Header.Set(proto.HeaderVersion, proto.VersionRange())
+
+	res, err = client.httpClient.Do(req)
+	if err != nil {
+		return
+	}
+
+	if res.StatusCode != 200 {
+		_ = res.Body.Close()
+		err = fmt.Errorf("stream request failed: %s", res.Status)
+		return
+	}
+
+	stream = NewStream(client, request.GetKey())
+	stream.body = res.Body
+	stream.reader = bufio.NewReader(res.Body)
+
+	return
+}
@@ -0,0 +1,193 @@
+package client_test
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/heartbytenet/go-lerpc/pkg/client"
+	"github.com/heartbytenet/go-lerpc/pkg/proto"
+	"github.com/heartbytenet/go-lerpc/pkg/server"
+)
+
+func testStreamClient(t *testing.T, buffer int, setup func(instance *server.Server)) *client.Client {
+	settings := server.NewSettingsDefault()
+	settings.StreamBuffer = buffer
+
+	instance := server.NewServerWithSettings(settings)
+	setup(instance)
+	instance.Routes()
+
+	test := httptest.NewServer(instance.GetEngine())
+	t.Cleanup(test.Close)
+
+	result := client.NewClient(client.ClientModeWs, strings.TrimPrefix(test.URL, "http://"), "")
+	if err := result.Open(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = result.Close()
+	})
+
+	return result
+}
+
+func TestStreamSendFlowControl(t *testing.T) {
+	instance := testStreamClient(t, 2, func(instance *server.Server) {
+		instance.AddStreamHandler(server.NewStreamHandlerWith("test", "sum", nil,
+			func(ctx *server.RequestContext, stream *server.Stream) error {
+				total := 0.0
+
+				for {
+					data, err := stream.Recv()
+					if err != nil {
+						return stream.Send(map[string]any{"total": total})
+					}
+
+					time.Sleep(time.Millisecond)
+					total += data["value"].(float64)
+				}
+			}))
+	})
+
+	stream, err := instance.Stream(proto.NewRequest().
+		WithNamespace("test").
+		WithMethod("sum"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 1; i <= 32; i++ {
+		if err = stream.Send(map[string]any{"value": i}); err != nil {
+			t.Fatalf("send %d: %v", i, err)
+		}
+	}
+
+	if err = stream.CloseSend(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !stream.Next() {
+		t.Fatalf("stream ended early: %v", stream.Err())
+	}
+
+	if total := stream.Value()["total"]; total != float64(528) {
+		t.Fatalf("server received a total of %v", total)
+	}
+
+	if stream.Next() || stream.Err() != nil {
+		t.Fatalf("stream did not end cleanly: %v", stream.Err())
+	}
+}
+
+func TestStreamSendAfterEnd(t *testing.T) {
+	instance := testStreamClient(t, 1, func(instance *server.Server) {
+		instance.AddStreamHandler(server.NewStreamHandlerWith("test", "end", nil,
+			func(ctx *server.RequestContext, stream *server.Stream) error {
+				return nil
+			}))
+	})
+
+	stream, err := instance.Stream(proto.NewRequest().
+		WithNamespace("test").
+		WithMethod("end"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		for {
+			if err := stream.Send(map[string]any{"value": 1}); err != nil {
+				done <- err
+				return
+			}
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("send blocked after the server ended the stream")
+	}
+}
+
+func TestStreamCloseWakesNext(t *testing.T) {
+	instance := testStreamClient(t, 1, func(instance *server.Server) {
+		instance.AddStreamHandler(server.NewStreamHandlerWith("test", "idle", nil,
+			func(ctx *server.RequestContext, stream *server.Stream) error {
+				<-ctx.Done()
+				return nil
+			}))
+	})
+
+	stream, err := instance.Stream(proto.NewRequest().
+		WithNamespace("test").
+		WithMethod("idle"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan bool, 1)
+	go func() {
+		done <- stream.Next()
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	_ = stream.Close()
+
+	select {
+	case flag := <-done:
+		if flag {
+			t.Fatal("closed stream yielded a value")
+		}
+
+	case <-time.After(time.Second):
+		t.Fatal("close did not wake a blocked Next")
+	}
+}
+
+func TestStreamErrorResult(t *testing.T) {
+	release := make(chan struct{})
+
+	instance := testStreamClient(t, 1, func(instance *server.Server) {
+		instance.AddHandler(server.NewHandlerWith("test", "wait", nil,
+			func(ctx *server.RequestContext, request proto.Request) proto.Result {
+				<-release
+				return proto.NewResult().WithCode(proto.ResultCodeSuccess)
+			}))
+	})
+	defer close(release)
+
+	_, err := instance.Execute(proto.NewRequest().
+		WithKey("shared").
+		WithNamespace("test").
+		WithMethod("wait"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stream, err := instance.Stream(proto.NewRequest().
+		WithKey("shared").
+		WithNamespace("test").
+		WithMethod("missing"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan bool, 1)
+	go func() {
+		done <- stream.Next()
+	}()
+
+	select {
+	case flag := <-done:
+		if flag || stream.Err() == nil || !strings.Contains(stream.Err().Error(), server.ErrorKeyInFlight) {
+			t.Fatalf("expected key in flight error, got %v", stream.Err())
+		}
+
+	case <-time.After(time.Second):
+		t.Fatal("error result was not routed to the stream")
+	}
+}
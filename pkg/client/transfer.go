@@ -0,0 +1,314 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/heartbytenet/bblib/containers/optionals"
+	"github.com/heartbytenet/go-lerpc/pkg/proto"
+)
+
+var (
+	ErrTransferAborted = errors.New("transfer aborted")
+)
+
+type TransferProgressFunction func(received int64, size int64)
+
+type Transfer struct {
+	client     *Client
+	key        string
+	fnProgress TransferProgressFunction
+
+	mutex       sync.Mutex
+	signal      chan struct{}
+	chunks      []proto.Chunk
+	buffer      []byte
+	count       uint32
+	size        int64
+	contentType string
+	received    int64
+	done        bool
+	err         error
+}
+
+func NewTransfer(client *Client, key string, fnProgress TransferProgressFunction) *Transfer {
+	return &Transfer{
+		client:     client,
+		key:        key,
+		fnProgress: fnProgress,
+
+		signal: make(chan struct{}, 1),
+		chunks: make([]proto.Chunk, 0),
+		size:   -1,
+	}
+}
+
+func (transfer *Transfer) GetKey() string {
+	return transfer.key
+}
+
+func (transfer *Transfer) GetSize() int64 {
+	transfer.mutex.Lock()
+	defer transfer.mutex.Unlock()
+
+	return transfer.size
+}
+
+func (transfer *Transfer) GetContentType() string {
+	transfer.mutex.Lock()
+	defer transfer.mutex.Unlock()
+
+	return transfer.contentType
+}
+
+func (transfer *Transfer) notify() {
+	select {
+	case transfer.signal <- struct{}{}:
+	default:
+	}
+}
+
+func (transfer *Transfer) Start(size int64, contentType string) {
+	transfer.mutex.Lock()
+	defer transfer.mutex.Unlock()
+
+	transfer.size = size
+	transfer.contentType = contentType
+}
+
+func (transfer *Transfer) Push(chunk proto.Chunk) {
+	transfer.mutex.Lock()
+
+	if chunk.Seq != transfer.count {
+		transfer.mutex.Unlock()
+		transfer.Finish(fmt.Errorf("transfer chunk out of sequence: %d", chunk.Seq))
+		return
+	}
+
+	transfer.count++
+	transfer.chunks = append(transfer.chunks, chunk)
+	transfer.mutex.Unlock()
+
+	transfer.notify()
+}
+
+func (transfer *Transfer) End(count uint32) {
+	transfer.mutex.Lock()
+	received := transfer.count
+	transfer.mutex.Unlock()
+
+	if count != received {
+		transfer.Finish(fmt.Errorf("transfer incomplete: %d of %d chunks", received, count))
+		return
+	}
+
+	transfer.Finish(nil)
+}
+
+func (transfer *Transfer) Finish(err error) {
+	transfer.mutex.Lock()
+
+	if transfer.done {
+		transfer.mutex.Unlock()
+		return
+	}
+
+	transfer.done = true
+	transfer.err = err
+	transfer.mutex.Unlock()
+
+	transfer.client.RemoveTransfer(transfer.key)
+	transfer.notify()
+}
+
+func (transfer *Transfer) Read(buffer []byte) (size int, err error) {
+	for {
+		transfer.mutex.Lock()
+
+		if len(transfer.buffer) > 0 {
+			size = copy(buffer, transfer.buffer)
+			transfer.buffer = transfer.buffer[size:]
+			transfer.received += int64(size)
+			received, total := transfer.received, transfer.size
+			transfer.mutex.Unlock()
+
+			if transfer.fnProgress != nil {
+				transfer.fnProgress(received, total)
+			}
+
+			return
+		}
+
+		if len(transfer.chunks) > 0 {
+			chunk := transfer.chunks[0]
+			transfer.chunks = transfer.chunks[1:]
+			transfer.buffer = chunk.Data
+			transfer.mutex.Unlock()
+
+			_ = transfer.client.WriteFrame(proto.NewFrame(proto.FrameKindTransferAck).
+				WithKey(transfer.key).
+				WithSeq(chunk.Seq))
+			continue
+		}
+
+		if transfer.done {
+			err = transfer.err
+			if err == nil {
+				err = io.EOF
+			}
+
+			transfer.mutex.Unlock()
+			return
+		}
+
+		transfer.mutex.Unlock()
+
+		<-transfer.signal
+	}
+}
+
+func (transfer *Transfer) Close() (err error) {
+	transfer.mutex.Lock()
+	done := transfer.done
+	transfer.mutex.Unlock()
+
+	if done {
+		return
+	}
+
+	transfer.Finish(ErrTransferAborted)
+
+	return transfer.client.WriteFrame(proto.NewFrame(proto.FrameKindTransferError).
+		WithKey(transfer.key).
+		WithMessage(ErrTransferAborted.Error()))
+}
+
+func (client *Client) GetTransfer(key string) (result optionals.Optional[*Transfer]) {
+	result = optionals.None[*Transfer]()
+
+	client.transfers.Apply(func(data map[string]*Transfer) {
+		transfer, flag := data[key]
+		if !flag {
+			return
+		}
+
+		result = optionals.Some(transfer)
+	})
+
+	return
+}
+
+func (client *Client) RemoveTransfer(key string) {
+	client.transfers.Map(func(data map[string]*Transfer) map[string]*Transfer {
+		delete(data, key)
+		return data
+	})
+}
+
+func (client *Client) GetUrlDownload(target string) string {
+	scheme := "http"
+	if client.mode == ClientModeHttps || client.mode == ClientModeWss {
+		scheme = "https"
+	}
+
+	return fmt.Sprintf("%s://%s/%s", scheme, client.remote, strings.TrimPrefix(target, "/"))
+}
+
+func (client *Client) Download(target string) (reader io.ReadCloser, err error) {
+	return client.DownloadWithProgress(target, nil)
+}
+
+func (client *Client) DownloadWithProgress(target string, fnProgress TransferProgressFunction) (reader io.ReadCloser, err error) {
+	switch client.GetMode() {
+	case ClientModeWs, ClientModeWss:
+		return client.DownloadWs(target, fnProgress)
+
+	default:
+		return client.DownloadHttp(target, fnProgress)
+	}
+}
+
+func (client *Client) DownloadWs(target string, fnProgress TransferProgressFunction) (reader io.ReadCloser, err error) {
+	var (
+		transfer *Transfer
+	)
+
+	transfer = NewTransfer(client, client.NextKey(), fnProgress)
+
+	client.transfers.Map(func(data map[string]*Transfer) map[string]*Transfer {
+		data[transfer.GetKey()] = transfer
+		return data
+	})
+
+	err = client.WriteFrame(proto.NewFrame(proto.FrameKindTransferRequest).
+		WithKey(transfer.GetKey()).
+		WithToken(client.token).
+		WithUrl(target))
+	if err != nil {
+		client.RemoveTransfer(transfer.GetKey())
+		return
+	}
+
+	reader = transfer
+	return
+}
+
+func (client *Client) DownloadHttp(target string, fnProgress TransferProgressFunction) (reader io.ReadCloser, err error) {
+	var (
+		req *http.Request
+		res *http.Response
+	)
+
+	req, err = http.NewRequest("GET", client.GetUrlDownload(target), nil)
+	if err != nil {
+		return
+	}
+
+	if client.token != "" {
+		req.Header.Set("Authorization", "Bearer "+client.token)
+	}
+
+	res, err = client.httpClient.Do(req)
+	if err != nil {
+		return
+	}
+
+	if res.StatusCode != 200 {
+		_ = res.Body.Close()
+		err = fmt.Errorf("download request failed: %s", res.Status)
+		return
+	}
+
+	reader = res.Body
+	if fnProgress != nil {
+		reader = &progressReader{
+			ReadCloser: res.Body,
+			size:       res.ContentLength,
+			fnProgress: fnProgress,
+		}
+	}
+
+	return
+}
+
+type progressReader struct {
+	io.ReadCloser
+
+	size       int64
+	received   int64
+	fnProgress TransferProgressFunction
+}
+
+func (reader *progressReader) Read(buffer []byte) (size int, err error) {
+	size, err = reader.ReadCloser.Read(buffer)
+	if size > 0 {
+		reader.received += int64(size)
+		reader.fnProgress(reader.received, reader.size)
+	}
+
+	return
+}
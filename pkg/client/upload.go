@@ -0,0 +1,162 @@
+package client
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/heartbytenet/go-lerpc/pkg/proto"
+)
+
+var (
+	UploadChunkSize  int64 = 4 << 20
+	UploadRetryLimit       = 5
+	UploadRetryDelay       = time.Second
+)
+
+func (client *Client) GetUrlUpload(key string) string {
+	scheme := "http"
+	if client.mode == ClientModeHttps || client.mode == ClientModeWss {
+		scheme = "https"
+	}
+
+	return fmt.Sprintf("%s://%s/upload?key=%s", scheme, client.remote, url.QueryEscape(key))
+}
+
+func (client *Client) UploadOffset(key string) (offset int64, length int64, err error) {
+	var (
+		req *http.Request
+		res *http.Response
+	)
+
+	req, err = http.NewRequest("HEAD", client.GetUrlUpload(key), nil)
+	if err != nil {
+		return
+	}
+
+	res, err = client.httpClient.Do(req)
+	if err != nil {
+		return
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != 200 {
+		err = fmt.Errorf("upload offset request failed: %s", res.Status)
+		return
+	}
+
+	offset, err = strconv.ParseInt(res.Header.Get(proto.HeaderUploadOffset), 10, 64)
+	if err != nil {
+		return
+	}
+
+	length, err = strconv.ParseInt(res.Header.Get(proto.HeaderUploadLength), 10, 64)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func (client *Client) UploadChunk(key string, offset int64, data []byte, fileSum []byte) (next int64, err error) {
+	var (
+		req *http.Request
+		res *http.Response
+		sum [sha256.Size]byte
+	)
+
+	req, err = http.NewRequest("PATCH", client.GetUrlUpload(key), bytes.NewReader(data))
+	if err != nil {
+		return
+	}
+
+	sum = sha256.Sum256(data)
+
+	req.Header.Set("Content-Type", proto.ContentTypeUpload)
+	req.Header.Set(proto.HeaderUploadOffset, strconv.FormatInt(offset, 10))
+	req.Header.Set(proto.HeaderUploadChecksum, proto.FormatUploadChecksum(sum[:]))
+	if fileSum != nil {
+		req.Header.Set(proto.HeaderUploadDigest, proto.FormatUploadChecksum(fileSum))
+	}
+
+	res, err = client.httpClient.Do(req)
+	if err != nil {
+		return
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != 204 {
+		err = fmt.Errorf("upload chunk request failed: %s", res.Status)
+		return
+	}
+
+	next, err = strconv.ParseInt(res.Header.Get(proto.HeaderUploadOffset), 10, 64)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func (client *Client) Upload(key string, reader io.ReadSeeker) (err error) {
+	var (
+		hash    = sha256.New()
+		fileSum []byte
+		offset  int64
+		length  int64
+		retries int
+		chunk   []byte
+		size    int
+	)
+
+	_, err = io.Copy(hash, reader)
+	if err != nil {
+		return
+	}
+	fileSum = hash.Sum(nil)
+
+	chunk = make([]byte, UploadChunkSize)
+
+	for {
+		offset, length, err = client.UploadOffset(key)
+		if err != nil {
+			if retries++; retries > UploadRetryLimit {
+				return
+			}
+
+			time.Sleep(UploadRetryDelay)
+			continue
+		}
+
+		if offset >= length {
+			return nil
+		}
+
+		_, err = reader.Seek(offset, io.SeekStart)
+		if err != nil {
+			return
+		}
+
+		size, err = io.ReadFull(reader, chunk)
+		if err != nil && err != io.ErrUnexpectedEOF {
+			return
+		}
+
+		_, err = client.UploadChunk(key, offset, chunk[:size], fileSum)
+		if err != nil {
+			if retries++; retries > UploadRetryLimit {
+				return
+			}
+
+			time.Sleep(UploadRetryDelay)
+			continue
+		}
+
+		retries = 0
+	}
+}
@@ -0,0 +1,106 @@
+package client_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/heartbytenet/go-lerpc/pkg/client"
+	"github.com/heartbytenet/go-lerpc/pkg/server"
+)
+
+func testUploadServer(t *testing.T, key string, data []byte) (*client.Client, string) {
+	sum := sha256.Sum256(data)
+	path := filepath.Join(t.TempDir(), "upload.bin")
+
+	instance := server.NewServer()
+	instance.AddUploadHandler(server.NewUploadHandlerFile(key, time.Minute, int64(len(data)), sum[:], path, nil))
+	instance.Routes()
+
+	test := httptest.NewServer(instance.GetEngine())
+	t.Cleanup(test.Close)
+
+	return client.NewClient(client.ClientModeHttp, strings.TrimPrefix(test.URL, "http://"), ""), path
+}
+
+func testUploadChunkSize(t *testing.T, size int64) {
+	previous := client.UploadChunkSize
+	client.UploadChunkSize = size
+
+	t.Cleanup(func() {
+		client.UploadChunkSize = previous
+	})
+}
+
+func TestUploadRoundTrip(t *testing.T) {
+	testUploadChunkSize(t, 7)
+
+	data := []byte("a resumable upload split into several chunks")
+	instance, path := testUploadServer(t, "k", data)
+
+	err := instance.Upload("k", bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(result, data) {
+		t.Fatalf("unexpected file content %q", result)
+	}
+
+	offset, length, err := instance.UploadOffset("k")
+	if err != nil || offset != length {
+		t.Fatalf("completed upload reports %d of %d: %v", offset, length, err)
+	}
+}
+
+func TestUploadResume(t *testing.T) {
+	testUploadChunkSize(t, 8)
+
+	data := []byte("resume from the offset the server reports")
+	sum := sha256.Sum256(data)
+	instance, path := testUploadServer(t, "k", data)
+
+	next, err := instance.UploadChunk("k", 0, data[:8], sum[:])
+	if err != nil || next != 8 {
+		t.Fatalf("first chunk: next %d, err %v", next, err)
+	}
+
+	offset, length, err := instance.UploadOffset("k")
+	if err != nil || offset != 8 || length != int64(len(data)) {
+		t.Fatalf("unexpected offset %d of %d: %v", offset, length, err)
+	}
+
+	err = instance.Upload("k", bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(result, data) {
+		t.Fatalf("unexpected file content %q", result)
+	}
+}
+
+func TestUploadChunkConflict(t *testing.T) {
+	data := []byte("0123456789")
+	instance, _ := testUploadServer(t, "k", data)
+
+	_, err := instance.UploadChunk("k", 4, data[4:], nil)
+	if err == nil {
+		t.Fatal("chunk at the wrong offset was accepted")
+	}
+}
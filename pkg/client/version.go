@@ -0,0 +1,104 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/goccy/go-json"
+	"github.com/heartbytenet/bblib/containers/optionals"
+	"github.com/heartbytenet/go-lerpc/pkg/proto"
+)
+
+func (client *Client) Handshake() (capabilities proto.Capabilities, err error) {
+	var (
+		version uint32
+	)
+
+	switch client.GetMode() {
+	case ClientModeHttp, ClientModeHttps:
+		capabilities, err = client.HandshakeHttp()
+
+	case ClientModeWs, ClientModeWss:
+		capabilities, err = client.HandshakeWs()
+
+	default:
+		panic("invalid client mode")
+	}
+
+	if err != nil {
+		return
+	}
+
+	version, err = proto.SelectVersion(
+		proto.ProtocolVersionMin, proto.ProtocolVersion,
+		capabilities.VersionMin, capabilities.Version)
+	if err != nil {
+		return
+	}
+
+	client.version.Store(version)
+	client.capabilities.Set(optionals.Some(capabilities))
+
+	return
+}
+
+func (client *Client) GetVersion() uint32 {
+	return client.version.Load()
+}
+
+func (client *Client) GetCapabilities() optionals.Optional[proto.Capabilities] {
+	return client.capabilities.Get()
+}
+
+func (client *Client) HandshakeHttp() (capabilities proto.Capabilities, err error) {
+	var (
+		req *http.Request
+		res *http.Response
+	)
+
+	req, err = http.NewRequest("GET", client.GetUrlDownload("/capabilities"), nil)
+	if err != nil {
+		return
+	}
+
+	req.Header.Set(proto.HeaderVersion, proto.VersionRange())
+
+	res, err = client.httpClient.Do(req)
+	if err != nil {
+		return
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == 400 && res.Header.Get(proto.HeaderVersion) != "" {
+		err = fmt.Errorf("%w: server speaks version %s",
+			proto.ErrVersionUnsupported, res.Header.Get(proto.HeaderVersion))
+		return
+	}
+
+	if res.StatusCode != 200 {
+		err = fmt.Errorf("handshake failed: %s", res.Status)
+		return
+	}
+
+	err = json.NewDecoder(res.Body).Decode(&capabilities)
+	return
+}
+
+func (client *Client) HandshakeWs() (capabilities proto.Capabilities, err error) {
+	var (
+		result proto.Result
+	)
+
+	result, err = client.ExecuteFrameResult(proto.NewFrame(proto.FrameKindHello).
+		WithData(proto.NewCapabilities().ToData()))
+	if err != nil {
+		return
+	}
+
+	err = result.Check()
+	if err != nil {
+		return
+	}
+
+	return proto.CapabilitiesFromData(result.GetDataAll())
+}
@@ -0,0 +1,462 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/heartbytenet/go-lerpc/pkg/proto"
+)
+
+var (
+	ErrConnectionClosed = errors.New("connection closed")
+)
+
+func (client *Client) NewDialer() *websocket.Dialer {
+	return &websocket.Dialer{
+		Proxy:             http.ProxyFromEnvironment,
+		HandshakeTimeout:  websocket.DefaultDialer.HandshakeTimeout,
+		ReadBufferSize:    client.settings.ReadBufferSize,
+		WriteBufferSize:   client.settings.WriteBufferSize,
+		Subprotocols:      client.GetSubprotocols(),
+		EnableCompression: client.settings.EnableCompression,
+	}
+}
+
+func (client *Client) OpenWs() (err error) {
+	var (
+		conn     *websocket.Conn
+		response *http.Response
+		header   http.Header
+	)
+
+	header = http.Header{}
+	header.Set(proto.HeaderVersion, proto.VersionRange())
+	if client.settings.Origin != "" {
+		header.Set("Origin", client.settings.Origin)
+	}
+
+	conn, response, err = client.NewDialer().Dial(client.GetUrl(client.GetMode()), header)
+	if err != nil {
+		if response != nil && response.StatusCode == 400 && response.Header.Get(proto.HeaderVersion) != "" {
+			err = fmt.Errorf("%w: server speaks version %s: %w",
+				proto.ErrVersionUnsupported, response.Header.Get(proto.HeaderVersion), err)
+		}
+		return
+	}
+
+	if conn.Subprotocol() != "" && !slices.Contains(client.GetSubprotocols(), conn.Subprotocol()) {
+		_ = conn.Close()
+		err = fmt.Errorf("unsupported websocket subprotocol: %s", conn.Subprotocol())
+		return
+	}
+
+	client.codec.Set(proto.GetCodecBySubprotocol(conn.Subprotocol()).GetDefault(proto.CodecDefault))
+
+	if client.settings.MaxMessageSize > 0 {
+		conn.SetReadLimit(client.settings.MaxMessageSize)
+	}
+
+	if client.settings.PongTimeout > 0 {
+		_ = conn.SetReadDeadline(time.Now().Add(client.settings.PongTimeout))
+
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(client.settings.PongTimeout))
+		})
+	}
+
+	client.conn.Set(conn)
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		client.LoopRead(conn)
+	}()
+
+	go client.LoopPing(conn, done)
+
+	if response.Header.Get(proto.HeaderVersion) == "" {
+		return
+	}
+
+	_, err = client.Handshake()
+	if err != nil {
+		_ = client.Close()
+		return
+	}
+
+	return
+}
+
+func (client *Client) deadline() time.Time {
+	if client.settings.WriteTimeout <= 0 {
+		return time.Time{}
+	}
+
+	return time.Now().Add(client.settings.WriteTimeout)
+}
+
+func (client *Client) touch(conn *websocket.Conn) {
+	client.lastActivity.Store(time.Now().UnixNano())
+
+	if client.settings.PongTimeout > 0 {
+		_ = conn.SetReadDeadline(time.Now().Add(client.settings.PongTimeout))
+	}
+}
+
+func (client *Client) LoopPing(conn *websocket.Conn, done chan struct{}) {
+	var (
+		ticker *time.Ticker
+		ping   <-chan time.Time
+		idle   <-chan time.Time
+		err    error
+	)
+
+	if client.settings.PingInterval > 0 {
+		ticker = time.NewTicker(client.settings.PingInterval)
+		defer ticker.Stop()
+
+		ping = ticker.C
+	}
+
+	if client.settings.IdleTimeout > 0 {
+		idleTicker := time.NewTicker(client.settings.IdleTimeout / 4)
+		defer idleTicker.Stop()
+
+		idle = idleTicker.C
+	}
+
+	client.lastActivity.Store(time.Now().UnixNano())
+
+	for {
+		select {
+		case <-done:
+			return
+
+		case <-ping:
+			err = conn.WriteControl(websocket.PingMessage, nil, client.deadline())
+			if err != nil {
+				_ = conn.Close()
+				return
+			}
+
+		case <-idle:
+			if time.Since(time.Unix(0, client.lastActivity.Load())) > client.settings.IdleTimeout {
+				_ = conn.Close()
+				return
+			}
+		}
+	}
+}
+
+func (client *Client) Close() (err error) {
+	client.conn.Map(func(conn *websocket.Conn) *websocket.Conn {
+		if conn != nil {
+			err = conn.Close()
+		}
+
+		return nil
+	})
+
+	return
+}
+
+func (client *Client) NextKey() string {
+	return client.keyPrefix + strconv.FormatUint(client.counter.Add(1), 36)
+}
+
+func (client *Client) WriteMessage(kind int, data []byte) (err error) {
+	client.conn.Apply(func(conn *websocket.Conn) {
+		if conn == nil {
+			err = ErrConnectionClosed
+			return
+		}
+
+		_ = conn.SetWriteDeadline(client.deadline())
+
+		err = conn.WriteMessage(kind, data)
+		if err == nil {
+			client.lastActivity.Store(time.Now().UnixNano())
+		}
+	})
+
+	return
+}
+
+func (client *Client) WriteValue(value any) (err error) {
+	var (
+		codec proto.Codec
+		data  []byte
+	)
+
+	codec = client.GetCodec()
+
+	data, err = codec.Marshal(value)
+	if err != nil {
+		return
+	}
+
+	if codec.IsBinary() {
+		return client.WriteMessage(websocket.BinaryMessage, data)
+	}
+
+	return client.WriteMessage(websocket.TextMessage, data)
+}
+
+func (client *Client) WriteFrame(frame proto.Frame) error {
+	return client.WriteValue(frame)
+}
+
+func (client *Client) ExecuteWs(request proto.Request) (promise *proto.Promise[proto.Result], err error) {
+	promise = proto.NewPromise[proto.Result]()
+	request = client.PrepareRequest(request)
+
+	err = client.AddPending(request.GetKey(), promise)
+	if err != nil {
+		return
+	}
+
+	err = client.WriteValue(request)
+	if err != nil {
+		client.RemovePending(request.GetKey())
+		return
+	}
+
+	return
+}
+
+func (client *Client) ExecuteFrame(frame proto.Frame) (err error) {
+	var (
+		result proto.Result
+	)
+
+	result, err = client.ExecuteFrameResult(frame)
+	if err != nil {
+		return
+	}
+
+	return result.Check()
+}
+
+func (client *Client) ExecuteFrameResult(frame proto.Frame) (result proto.Result, err error) {
+	var (
+		promise *proto.Promise[proto.Result]
+	)
+
+	promise = proto.NewPromise[proto.Result]()
+	frame = frame.WithKey(client.NextKey())
+
+	err = client.AddPending(frame.GetKey(), promise)
+	if err != nil {
+		return
+	}
+
+	err = client.WriteFrame(frame)
+	if err != nil {
+		client.RemovePending(frame.GetKey())
+		return
+	}
+
+	return promise.Await()
+}
+
+func (client *Client) Cancel(key string) (err error) {
+	promise := client.RemovePending(key)
+	if promise != nil {
+		promise.Failed(proto.ErrCancelled)
+	}
+
+	return client.WriteFrame(proto.NewFrame(proto.FrameKindCancel).
+		WithKey(key))
+}
+
+func (client *Client) AddPending(key string, promise *proto.Promise[proto.Result]) (err error) {
+	client.pending.Map(func(data map[string]*proto.Promise[proto.Result]) map[string]*proto.Promise[proto.Result] {
+		if _, flag := data[key]; flag {
+			err = fmt.Errorf("%w: %s", proto.ErrKeyInFlight, key)
+			return data
+		}
+
+		data[key] = promise
+		return data
+	})
+
+	return
+}
+
+func (client *Client) RemovePending(key string) (promise *proto.Promise[proto.Result]) {
+	client.pending.Map(func(data map[string]*proto.Promise[proto.Result]) map[string]*proto.Promise[proto.Result] {
+		promise = data[key]
+		delete(data, key)
+		return data
+	})
+
+	return
+}
+
+func (client *Client) LoopRead(conn *websocket.Conn) {
+	var (
+		kind int
+		data []byte
+		err  error
+	)
+
+	defer client.FailAll(ErrConnectionClosed)
+	defer client.conn.Map(func(current *websocket.Conn) *websocket.Conn {
+		if current != conn {
+			return current
+		}
+
+		_ = conn.Close()
+		return nil
+	})
+
+	for {
+		kind, data, err = conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		client.touch(conn)
+
+		if kind == websocket.BinaryMessage && len(data) > 0 && data[0] == proto.ChunkMarker {
+			client.HandleChunk(data)
+			continue
+		}
+
+		client.HandleMessage(data)
+	}
+}
+
+func (client *Client) HandleMessage(data []byte) {
+	var (
+		header proto.FrameHeader
+		frame  proto.Frame
+		result proto.Result
+		stream *Stream
+		codec  proto.Codec
+		err    error
+	)
+
+	codec = client.GetCodec()
+
+	err = codec.Unmarshal(data, &header)
+	if err != nil {
+		return
+	}
+
+	if header.Kind != proto.FrameKindMessage {
+		err = codec.Unmarshal(data, &frame)
+		if err != nil {
+			return
+		}
+
+		client.HandleFrame(frame)
+		return
+	}
+
+	err = codec.Unmarshal(data, &result)
+	if err != nil {
+		return
+	}
+
+	stream, err = client.GetStream(result.GetKey()).GetTry()
+	if err == nil && result.GetCode() == proto.ResultCodeError {
+		stream.Push(proto.NewFrame(proto.FrameKindStreamError).
+			WithKey(stream.GetKey()).
+			WithMessage(result.GetMessage()))
+		return
+	}
+
+	promise := client.RemovePending(result.GetKey())
+	if promise == nil {
+		return
+	}
+
+	promise.Complete(result)
+}
+
+func (client *Client) HandleFrame(frame proto.Frame) {
+	switch frame.GetKind() {
+	case proto.FrameKindNotify:
+		client.HandleNotify(frame.GetTopic(), frame.GetDataAll())
+
+	case proto.FrameKindStreamData, proto.FrameKindStreamEnd, proto.FrameKindStreamError:
+		client.GetStream(frame.GetKey()).IfPresent(func(stream *Stream) {
+			stream.Push(frame)
+		})
+
+	case proto.FrameKindStreamAck:
+		client.GetStream(frame.GetKey()).IfPresent(func(stream *Stream) {
+			stream.Ack(frame.GetSeq())
+		})
+
+	case proto.FrameKindTransferStart:
+		client.GetTransfer(frame.GetKey()).IfPresent(func(transfer *Transfer) {
+			transfer.Start(frame.GetSize(), frame.GetMessage())
+		})
+
+	case proto.FrameKindTransferEnd:
+		client.GetTransfer(frame.GetKey()).IfPresent(func(transfer *Transfer) {
+			transfer.End(frame.GetSeq())
+		})
+
+	case proto.FrameKindTransferError:
+		client.GetTransfer(frame.GetKey()).IfPresent(func(transfer *Transfer) {
+			transfer.Finish(errors.New(frame.GetMessage()))
+		})
+	}
+}
+
+func (client *Client) HandleChunk(data []byte) {
+	chunk, err := proto.DecodeChunk(data)
+	if err != nil {
+		return
+	}
+
+	client.GetTransfer(chunk.Key).IfPresent(func(transfer *Transfer) {
+		transfer.Push(chunk)
+	})
+}
+
+func (client *Client) FailAll(err error) {
+	var (
+		promises  map[string]*proto.Promise[proto.Result]
+		transfers map[string]*Transfer
+		streams   map[string]*Stream
+	)
+
+	client.pending.Map(func(data map[string]*proto.Promise[proto.Result]) map[string]*proto.Promise[proto.Result] {
+		promises = data
+		return map[string]*proto.Promise[proto.Result]{}
+	})
+
+	client.transfers.Map(func(data map[string]*Transfer) map[string]*Transfer {
+		transfers = data
+		return map[string]*Transfer{}
+	})
+
+	client.streams.Map(func(data map[string]*Stream) map[string]*Stream {
+		streams = data
+		return map[string]*Stream{}
+	})
+
+	for _, promise := range promises {
+		promise.Failed(err)
+	}
+
+	for _, transfer := range transfers {
+		transfer.Finish(err)
+	}
+
+	for _, stream := range streams {
+		stream.Push(proto.NewFrame(proto.FrameKindStreamError).
+			WithKey(stream.GetKey()).
+			WithMessage(err.Error()))
+	}
+}
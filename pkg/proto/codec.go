@@ -0,0 +1,307 @@
+package proto
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"reflect"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/goccy/go-json"
+	"github.com/heartbytenet/bblib/containers/optionals"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+const (
+	ContentTypeJson    = "application/json"
+	ContentTypeMsgpack = "application/msgpack"
+	ContentTypeCbor    = "application/cbor"
+
+	SubprotocolMsgpack = Subprotocol + ".msgpack"
+	SubprotocolCbor    = Subprotocol + ".cbor"
+)
+
+var (
+	CodecDefault Codec = CodecJson{}
+
+	Codecs = []Codec{
+		CodecJson{},
+		CodecMsgpack{},
+		CodecCbor{},
+		CodecProtobuf{},
+	}
+
+	cborEncMode, _ = cbor.EncOptions{}.EncMode()
+	cborDecMode, _ = cbor.DecOptions{
+		DefaultMapType: reflect.TypeOf(map[string]any{}),
+	}.DecMode()
+)
+
+type Codec interface {
+	GetName() string
+	GetContentType() string
+	GetSubprotocol() string
+	IsBinary() bool
+	IsArray(data []byte) bool
+	Marshal(value any) ([]byte, error)
+	Unmarshal(data []byte, value any) error
+}
+
+func GetCodecByContentType(value string) optionals.Optional[Codec] {
+	mediaType, _, err := mime.ParseMediaType(value)
+	if err != nil {
+		return optionals.None[Codec]()
+	}
+
+	switch mediaType {
+	case "application/x-msgpack":
+		mediaType = ContentTypeMsgpack
+
+	case "application/protobuf":
+		mediaType = ContentTypeProtobuf
+	}
+
+	for _, codec := range Codecs {
+		if codec.GetContentType() == mediaType {
+			return optionals.Some(codec)
+		}
+	}
+
+	return optionals.None[Codec]()
+}
+
+func GetCodecByName(value string) optionals.Optional[Codec] {
+	for _, codec := range Codecs {
+		if codec.GetName() == value {
+			return optionals.Some(codec)
+		}
+	}
+
+	return optionals.None[Codec]()
+}
+
+func GetCodecBySubprotocol(value string) optionals.Optional[Codec] {
+	for _, codec := range Codecs {
+		if codec.GetSubprotocol() == value {
+			return optionals.Some(codec)
+		}
+	}
+
+	return optionals.None[Codec]()
+}
+
+type CodecJson struct{}
+
+func (CodecJson) GetName() string {
+	return "json"
+}
+
+func (CodecJson) GetContentType() string {
+	return ContentTypeJson
+}
+
+func (CodecJson) GetSubprotocol() string {
+	return Subprotocol
+}
+
+func (CodecJson) IsBinary() bool {
+	return false
+}
+
+func (CodecJson) IsArray(data []byte) bool {
+	data = bytes.TrimLeft(data, " \t\r\n")
+
+	return len(data) > 0 && data[0] == '['
+}
+
+func (CodecJson) Marshal(value any) ([]byte, error) {
+	return json.Marshal(value)
+}
+
+func (CodecJson) Unmarshal(data []byte, value any) error {
+	return json.Unmarshal(data, value)
+}
+
+type CodecMsgpack struct{}
+
+func (CodecMsgpack) GetName() string {
+	return "msgpack"
+}
+
+func (CodecMsgpack) GetContentType() string {
+	return ContentTypeMsgpack
+}
+
+func (CodecMsgpack) GetSubprotocol() string {
+	return SubprotocolMsgpack
+}
+
+func (CodecMsgpack) IsBinary() bool {
+	return true
+}
+
+func (CodecMsgpack) IsArray(data []byte) bool {
+	if len(data) < 1 {
+		return false
+	}
+
+	return data[0]&0xf0 == 0x90 || data[0] == 0xdc || data[0] == 0xdd
+}
+
+func (CodecMsgpack) Marshal(value any) (data []byte, err error) {
+	var (
+		buffer bytes.Buffer
+	)
+
+	encoder := msgpack.NewEncoder(&buffer)
+	encoder.SetCustomStructTag("json")
+	encoder.SetOmitEmpty(true)
+
+	err = encoder.Encode(value)
+	if err != nil {
+		return
+	}
+
+	data = buffer.Bytes()
+	return
+}
+
+func (CodecMsgpack) Unmarshal(data []byte, value any) error {
+	decoder := msgpack.NewDecoder(bytes.NewReader(data))
+	decoder.SetCustomStructTag("json")
+	decoder.UseLooseInterfaceDecoding(true)
+
+	err := decoder.Decode(value)
+	if err != nil {
+		return err
+	}
+
+	NormalizeValue(value)
+	return nil
+}
+
+type CodecCbor struct{}
+
+func (CodecCbor) GetName() string {
+	return "cbor"
+}
+
+func (CodecCbor) GetContentType() string {
+	return ContentTypeCbor
+}
+
+func (CodecCbor) GetSubprotocol() string {
+	return SubprotocolCbor
+}
+
+func (CodecCbor) IsBinary() bool {
+	return true
+}
+
+func (CodecCbor) IsArray(data []byte) bool {
+	return len(data) > 0 && data[0]>>5 == 4
+}
+
+func (CodecCbor) Marshal(value any) ([]byte, error) {
+	return cborEncMode.Marshal(value)
+}
+
+func (CodecCbor) Unmarshal(data []byte, value any) error {
+	err := cborDecMode.Unmarshal(data, value)
+	if err != nil {
+		return err
+	}
+
+	NormalizeValue(value)
+	return nil
+}
+
+func NormalizeValue(value any) {
+	switch target := value.(type) {
+	case *Request:
+		target.Params = NormalizeMap(target.Params)
+
+	case *[]Request:
+		for index := range *target {
+			NormalizeValue(&(*target)[index])
+		}
+
+	case *Result:
+		target.Data = NormalizeMap(target.Data)
+
+	case *[]Result:
+		for index := range *target {
+			NormalizeValue(&(*target)[index])
+		}
+
+	case *Frame:
+		target.Data = NormalizeMap(target.Data)
+
+	case *map[string]any:
+		*target = NormalizeMap(*target)
+
+	case *[]any:
+		for index := range *target {
+			(*target)[index] = NormalizeNumber((*target)[index])
+		}
+
+	case *any:
+		*target = NormalizeNumber(*target)
+	}
+}
+
+func NormalizeMap(data map[string]any) map[string]any {
+	for key, value := range data {
+		data[key] = NormalizeNumber(value)
+	}
+
+	return data
+}
+
+func NormalizeNumber(value any) any {
+	switch number := value.(type) {
+	case int:
+		return float64(number)
+	case int8:
+		return float64(number)
+	case int16:
+		return float64(number)
+	case int32:
+		return float64(number)
+	case int64:
+		return float64(number)
+	case uint:
+		return float64(number)
+	case uint8:
+		return float64(number)
+	case uint16:
+		return float64(number)
+	case uint32:
+		return float64(number)
+	case uint64:
+		return float64(number)
+	case float32:
+		return float64(number)
+
+	case map[string]any:
+		return NormalizeMap(number)
+
+	case map[any]any:
+		data := make(map[string]any, len(number))
+		for key, entry := range number {
+			data[fmt.Sprint(key)] = NormalizeNumber(entry)
+		}
+
+		return data
+
+	case []any:
+		for index := range number {
+			number[index] = NormalizeNumber(number[index])
+		}
+
+		return number
+
+	default:
+		return value
+	}
+}
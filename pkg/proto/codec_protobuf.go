@@ -0,0 +1,573 @@
+package proto
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/goccy/go-json"
+	"google.golang.org/protobuf/encoding/protowire"
+	protobuf "google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	ContentTypeProtobuf = "application/x-protobuf"
+
+	SubprotocolProtobuf = Subprotocol + ".protobuf"
+)
+
+const (
+	protobufEnvelopeRequest  protowire.Number = 1
+	protobufEnvelopeResult   protowire.Number = 2
+	protobufEnvelopeFrame    protowire.Number = 3
+	protobufEnvelopeRequests protowire.Number = 4
+	protobufEnvelopeResults  protowire.Number = 5
+)
+
+var (
+	ErrProtobufType     = errors.New("type cannot be encoded as a protobuf envelope")
+	ErrProtobufEnvelope = errors.New("protobuf envelope does not match the expected message")
+)
+
+type CodecProtobuf struct {
+	Inner Codec
+}
+
+func (CodecProtobuf) GetName() string {
+	return "protobuf"
+}
+
+func (CodecProtobuf) GetContentType() string {
+	return ContentTypeProtobuf
+}
+
+func (CodecProtobuf) GetSubprotocol() string {
+	return SubprotocolProtobuf
+}
+
+func (CodecProtobuf) IsBinary() bool {
+	return true
+}
+
+func (CodecProtobuf) IsArray(data []byte) bool {
+	num, _, size := protowire.ConsumeTag(data)
+	if size < 0 {
+		return false
+	}
+
+	return num == protobufEnvelopeRequests || num == protobufEnvelopeResults
+}
+
+func (codec CodecProtobuf) Marshal(value any) (data []byte, err error) {
+	var (
+		num  protowire.Number
+		body []byte
+	)
+
+	switch value := value.(type) {
+	case Request:
+		num = protobufEnvelopeRequest
+		body, err = codec.appendRequest(nil, value)
+
+	case *Request:
+		num = protobufEnvelopeRequest
+		body, err = codec.appendRequest(nil, *value)
+
+	case Result:
+		num = protobufEnvelopeResult
+		body, err = codec.appendResult(nil, value)
+
+	case *Result:
+		num = protobufEnvelopeResult
+		body, err = codec.appendResult(nil, *value)
+
+	case Frame:
+		num = protobufEnvelopeFrame
+		body, err = codec.appendFrame(nil, value)
+
+	case *Frame:
+		num = protobufEnvelopeFrame
+		body, err = codec.appendFrame(nil, *value)
+
+	case []Request:
+		num = protobufEnvelopeRequests
+		for _, request := range value {
+			var message []byte
+
+			message, err = codec.appendRequest(nil, request)
+			if err != nil {
+				return
+			}
+
+			body = protobufAppendMessage(body, 1, message)
+		}
+
+	case []Result:
+		num = protobufEnvelopeResults
+		for _, result := range value {
+			var message []byte
+
+			message, err = codec.appendResult(nil, result)
+			if err != nil {
+				return
+			}
+
+			body = protobufAppendMessage(body, 1, message)
+		}
+
+	default:
+		err = fmt.Errorf("%w: %T", ErrProtobufType, value)
+	}
+	if err != nil {
+		return
+	}
+
+	data = protowire.AppendTag(nil, num, protowire.BytesType)
+	data = protowire.AppendBytes(data, body)
+	return
+}
+
+func (codec CodecProtobuf) Unmarshal(data []byte, value any) (err error) {
+	var (
+		num  protowire.Number
+		body []byte
+	)
+
+	err = protobufFields(data, func(field protowire.Number, bytes []byte, _ uint64) error {
+		num, body = field, bytes
+		return nil
+	})
+	if err != nil {
+		return
+	}
+
+	switch value := value.(type) {
+	case *FrameHeader:
+		value.Kind = FrameKindMessage
+		if num != protobufEnvelopeFrame {
+			return
+		}
+
+		var frame Frame
+
+		err = codec.consumeFrame(body, &frame)
+		value.Kind = frame.Kind
+
+	case *Request:
+		if num != protobufEnvelopeRequest {
+			return ErrProtobufEnvelope
+		}
+
+		*value = Request{}
+		err = codec.consumeRequest(body, value)
+
+	case *Result:
+		if num != protobufEnvelopeResult {
+			return ErrProtobufEnvelope
+		}
+
+		*value = Result{}
+		err = codec.consumeResult(body, value)
+
+	case *Frame:
+		if num != protobufEnvelopeFrame {
+			return ErrProtobufEnvelope
+		}
+
+		*value = Frame{}
+		err = codec.consumeFrame(body, value)
+
+	case *[]Request:
+		if num != protobufEnvelopeRequests {
+			return ErrProtobufEnvelope
+		}
+
+		*value = []Request{}
+		err = protobufFields(body, func(field protowire.Number, bytes []byte, _ uint64) (err error) {
+			var request Request
+
+			err = codec.consumeRequest(bytes, &request)
+			*value = append(*value, request)
+			return
+		})
+
+	case *[]Result:
+		if num != protobufEnvelopeResults {
+			return ErrProtobufEnvelope
+		}
+
+		*value = []Result{}
+		err = protobufFields(body, func(field protowire.Number, bytes []byte, _ uint64) (err error) {
+			var result Result
+
+			err = codec.consumeResult(bytes, &result)
+			*value = append(*value, result)
+			return
+		})
+
+	default:
+		err = fmt.Errorf("%w: %T", ErrProtobufType, value)
+	}
+
+	return
+}
+
+func (codec CodecProtobuf) appendRequest(data []byte, request Request) (_ []byte, err error) {
+	data = protobufAppendString(data, 1, request.Token)
+	data = protobufAppendString(data, 2, request.Key)
+	data = protobufAppendString(data, 3, request.Namespace)
+	data = protobufAppendString(data, 4, request.Method)
+	data = protobufAppendBool(data, 6, request.Stream)
+	data = protobufAppendBool(data, 7, request.NoReply)
+	data = protobufAppendMeta(data, 9, request.Meta)
+	data = protobufAppendVarint(data, 10, uint64(request.Version))
+
+	return codec.appendValues(data, 5, 8, request.Params)
+}
+
+func (codec CodecProtobuf) appendResult(data []byte, result Result) (_ []byte, err error) {
+	data = protobufAppendString(data, 1, result.Key)
+	data = protobufAppendVarint(data, 2, uint64(result.Code))
+	data = protobufAppendString(data, 4, result.Message)
+	data = protobufAppendMeta(data, 6, result.Meta)
+
+	return codec.appendValues(data, 3, 5, result.Data)
+}
+
+func (codec CodecProtobuf) appendFrame(data []byte, frame Frame) (_ []byte, err error) {
+	data = protobufAppendVarint(data, 1, uint64(frame.Kind))
+	data = protobufAppendString(data, 2, frame.Key)
+	data = protobufAppendString(data, 3, frame.Token)
+	data = protobufAppendString(data, 4, frame.Topic)
+	data = protobufAppendString(data, 5, frame.Url)
+	data = protobufAppendVarint(data, 6, uint64(frame.Seq))
+	data = protobufAppendVarint(data, 7, uint64(frame.Size))
+	data = protobufAppendString(data, 9, frame.Message)
+
+	return codec.appendValues(data, 8, 10, frame.Data)
+}
+
+func (codec CodecProtobuf) appendValues(data []byte, numStruct protowire.Number, numRaw protowire.Number, values map[string]any) (_ []byte, err error) {
+	var (
+		message []byte
+	)
+
+	if values == nil {
+		return data, nil
+	}
+
+	if codec.Inner != nil {
+		message, err = codec.Inner.Marshal(values)
+		if err != nil {
+			return
+		}
+
+		raw := protobufAppendString(nil, 1, codec.Inner.GetName())
+		raw = protowire.AppendTag(raw, 2, protowire.BytesType)
+		raw = protowire.AppendBytes(raw, message)
+
+		return protobufAppendMessage(data, numRaw, raw), nil
+	}
+
+	value, err := protobufStruct(values)
+	if err != nil {
+		return
+	}
+
+	message, err = protobuf.Marshal(value)
+	if err != nil {
+		return
+	}
+
+	return protobufAppendMessage(data, numStruct, message), nil
+}
+
+func (codec CodecProtobuf) consumeRequest(data []byte, request *Request) error {
+	return protobufFields(data, func(num protowire.Number, bytes []byte, number uint64) (err error) {
+		switch num {
+		case 1:
+			request.Token = string(bytes)
+
+		case 2:
+			request.Key = string(bytes)
+
+		case 3:
+			request.Namespace = string(bytes)
+
+		case 4:
+			request.Method = string(bytes)
+
+		case 5:
+			request.Params, err = protobufConsumeStruct(bytes)
+
+		case 6:
+			request.Stream = number != 0
+
+		case 7:
+			request.NoReply = number != 0
+
+		case 8:
+			request.Params, err = protobufConsumeRaw(bytes)
+
+		case 9:
+			request.Meta, err = protobufConsumeMeta(request.Meta, bytes)
+
+		case 10:
+			request.Version = uint32(number)
+		}
+
+		return
+	})
+}
+
+func (codec CodecProtobuf) consumeResult(data []byte, result *Result) error {
+	return protobufFields(data, func(num protowire.Number, bytes []byte, number uint64) (err error) {
+		switch num {
+		case 1:
+			result.Key = string(bytes)
+
+		case 2:
+			result.Code = ResultCode(number)
+
+		case 3:
+			result.Data, err = protobufConsumeStruct(bytes)
+
+		case 4:
+			result.Message = string(bytes)
+
+		case 5:
+			result.Data, err = protobufConsumeRaw(bytes)
+
+		case 6:
+			result.Meta, err = protobufConsumeMeta(result.Meta, bytes)
+		}
+
+		return
+	})
+}
+
+func (codec CodecProtobuf) consumeFrame(data []byte, frame *Frame) error {
+	return protobufFields(data, func(num protowire.Number, bytes []byte, number uint64) (err error) {
+		switch num {
+		case 1:
+			frame.Kind = FrameKind(number)
+
+		case 2:
+			frame.Key = string(bytes)
+
+		case 3:
+			frame.Token = string(bytes)
+
+		case 4:
+			frame.Topic = string(bytes)
+
+		case 5:
+			frame.Url = string(bytes)
+
+		case 6:
+			frame.Seq = uint32(number)
+
+		case 7:
+			frame.Size = int64(number)
+
+		case 8:
+			frame.Data, err = protobufConsumeStruct(bytes)
+
+		case 9:
+			frame.Message = string(bytes)
+
+		case 10:
+			frame.Data, err = protobufConsumeRaw(bytes)
+		}
+
+		return
+	})
+}
+
+func protobufFields(data []byte, fn func(num protowire.Number, bytes []byte, number uint64) error) (err error) {
+	for len(data) > 0 {
+		num, kind, size := protowire.ConsumeTag(data)
+		if size < 0 {
+			return protowire.ParseError(size)
+		}
+
+		data = data[size:]
+
+		switch kind {
+		case protowire.VarintType:
+			var number uint64
+
+			number, size = protowire.ConsumeVarint(data)
+			if size < 0 {
+				return protowire.ParseError(size)
+			}
+
+			err = fn(num, nil, number)
+
+		case protowire.BytesType:
+			var bytes []byte
+
+			bytes, size = protowire.ConsumeBytes(data)
+			if size < 0 {
+				return protowire.ParseError(size)
+			}
+
+			err = fn(num, bytes, 0)
+
+		default:
+			size = protowire.ConsumeFieldValue(num, kind, data)
+			if size < 0 {
+				return protowire.ParseError(size)
+			}
+		}
+		if err != nil {
+			return
+		}
+
+		data = data[size:]
+	}
+
+	return
+}
+
+func protobufAppendString(data []byte, num protowire.Number, value string) []byte {
+	if value == "" {
+		return data
+	}
+
+	data = protowire.AppendTag(data, num, protowire.BytesType)
+	return protowire.AppendString(data, value)
+}
+
+func protobufAppendVarint(data []byte, num protowire.Number, value uint64) []byte {
+	if value == 0 {
+		return data
+	}
+
+	data = protowire.AppendTag(data, num, protowire.VarintType)
+	return protowire.AppendVarint(data, value)
+}
+
+func protobufAppendBool(data []byte, num protowire.Number, value bool) []byte {
+	if !value {
+		return data
+	}
+
+	return protobufAppendVarint(data, num, 1)
+}
+
+func protobufAppendMessage(data []byte, num protowire.Number, message []byte) []byte {
+	data = protowire.AppendTag(data, num, protowire.BytesType)
+	return protowire.AppendBytes(data, message)
+}
+
+func protobufAppendMeta(data []byte, num protowire.Number, meta map[string]string) []byte {
+	for key, value := range meta {
+		entry := protobufAppendString(nil, 1, key)
+		entry = protobufAppendString(entry, 2, value)
+
+		data = protobufAppendMessage(data, num, entry)
+	}
+
+	return data
+}
+
+func protobufConsumeMeta(meta map[string]string, data []byte) (_ map[string]string, err error) {
+	var (
+		key   string
+		value string
+	)
+
+	err = protobufFields(data, func(num protowire.Number, bytes []byte, _ uint64) error {
+		switch num {
+		case 1:
+			key = string(bytes)
+
+		case 2:
+			value = string(bytes)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return
+	}
+
+	if meta == nil {
+		meta = map[string]string{}
+	}
+
+	meta[key] = value
+	return meta, nil
+}
+
+func protobufStruct(values map[string]any) (value *structpb.Struct, err error) {
+	var (
+		data []byte
+	)
+
+	value, err = structpb.NewStruct(values)
+	if err == nil {
+		return
+	}
+
+	data, err = json.Marshal(values)
+	if err != nil {
+		return
+	}
+
+	values = map[string]any{}
+
+	err = json.Unmarshal(data, &values)
+	if err != nil {
+		return
+	}
+
+	return structpb.NewStruct(values)
+}
+
+func protobufConsumeStruct(data []byte) (values map[string]any, err error) {
+	var (
+		value structpb.Struct
+	)
+
+	err = protobuf.Unmarshal(data, &value)
+	if err != nil {
+		return
+	}
+
+	values = value.AsMap()
+	return
+}
+
+func protobufConsumeRaw(data []byte) (values map[string]any, err error) {
+	var (
+		name    string
+		message []byte
+		codec   Codec
+	)
+
+	err = protobufFields(data, func(num protowire.Number, bytes []byte, _ uint64) error {
+		switch num {
+		case 1:
+			name = string(bytes)
+
+		case 2:
+			message = bytes
+		}
+
+		return nil
+	})
+	if err != nil {
+		return
+	}
+
+	codec, err = GetCodecByName(name).GetTry()
+	if err != nil {
+		err = fmt.Errorf("unknown inner codec: %s", name)
+		return
+	}
+
+	err = codec.Unmarshal(message, &values)
+	return
+}
@@ -0,0 +1,168 @@
+package proto
+
+import (
+	"reflect"
+	"testing"
+)
+
+func testCodecs() []Codec {
+	return []Codec{
+		CodecJson{},
+		CodecMsgpack{},
+		CodecCbor{},
+		CodecProtobuf{},
+		CodecProtobuf{Inner: CodecMsgpack{}},
+	}
+}
+
+func testCodecName(codec Codec) string {
+	if protobuf, flag := codec.(CodecProtobuf); flag && protobuf.Inner != nil {
+		return codec.GetName() + "+" + protobuf.Inner.GetName()
+	}
+
+	return codec.GetName()
+}
+
+func testPayload() map[string]any {
+	return map[string]any{
+		"int":      42,
+		"negative": -7,
+		"float":    1.5,
+		"string":   "value",
+		"bool":     true,
+		"list":     []any{1, 2.5, "three"},
+		"nested": map[string]any{
+			"count": uint64(3),
+			"ratio": float32(0.25),
+		},
+	}
+}
+
+func testPayloadExpected() map[string]any {
+	return map[string]any{
+		"int":      float64(42),
+		"negative": float64(-7),
+		"float":    1.5,
+		"string":   "value",
+		"bool":     true,
+		"list":     []any{float64(1), 2.5, "three"},
+		"nested": map[string]any{
+			"count": float64(3),
+			"ratio": 0.25,
+		},
+	}
+}
+
+func TestCodecRequestRoundTrip(t *testing.T) {
+	for _, codec := range testCodecs() {
+		t.Run(testCodecName(codec), func(t *testing.T) {
+			request := NewRequest().
+				WithKey("k1").
+				WithNamespace("ns").
+				WithMethod("method").
+				WithVersion(ProtocolVersion).
+				WithMeta(map[string]string{"trace-id": "t1"})
+			request.Params = testPayload()
+
+			data, err := codec.Marshal(request)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			decoded := Request{}
+			err = codec.Unmarshal(data, &decoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if decoded.GetKey() != "k1" || decoded.GetNamespace() != "ns" || decoded.GetMethod() != "method" {
+				t.Fatalf("unexpected request %+v", decoded)
+			}
+
+			if decoded.GetVersion() != ProtocolVersion {
+				t.Fatalf("unexpected version %d", decoded.GetVersion())
+			}
+
+			if decoded.GetMeta("trace-id").GetDefault("") != "t1" {
+				t.Fatalf("unexpected meta %v", decoded.GetMetaAll())
+			}
+
+			if !reflect.DeepEqual(decoded.Params, testPayloadExpected()) {
+				t.Fatalf("unexpected params %#v", decoded.Params)
+			}
+		})
+	}
+}
+
+func TestCodecResultRoundTrip(t *testing.T) {
+	for _, codec := range testCodecs() {
+		t.Run(testCodecName(codec), func(t *testing.T) {
+			result := NewResult().
+				WithKey("k1").
+				WithCode(ResultCodeSuccess).
+				WithData(testPayload())
+
+			data, err := codec.Marshal([]Result{result})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			decoded := []Result{}
+			err = codec.Unmarshal(data, &decoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(decoded) != 1 || decoded[0].GetKey() != "k1" || decoded[0].GetCode() != ResultCodeSuccess {
+				t.Fatalf("unexpected results %+v", decoded)
+			}
+
+			if !reflect.DeepEqual(decoded[0].GetDataAll(), testPayloadExpected()) {
+				t.Fatalf("unexpected data %#v", decoded[0].GetDataAll())
+			}
+
+			if value := GetDataValue[float64](decoded[0], "int"); value.IsEmpty() || value.Get() != 42 {
+				t.Fatal("number is not decoded as float64")
+			}
+		})
+	}
+}
+
+func TestCodecFrameRoundTrip(t *testing.T) {
+	for _, codec := range testCodecs() {
+		t.Run(testCodecName(codec), func(t *testing.T) {
+			frame := NewFrame(FrameKindStreamData).
+				WithKey("k1").
+				WithData(testPayload())
+
+			data, err := codec.Marshal(frame)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			header := FrameHeader{}
+			err = codec.Unmarshal(data, &header)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if header.Kind != FrameKindStreamData {
+				t.Fatalf("unexpected frame kind %d", header.Kind)
+			}
+
+			decoded := Frame{}
+			err = codec.Unmarshal(data, &decoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if decoded.GetKey() != "k1" {
+				t.Fatalf("unexpected key %q", decoded.GetKey())
+			}
+
+			if !reflect.DeepEqual(decoded.GetDataAll(), testPayloadExpected()) {
+				t.Fatalf("unexpected data %#v", decoded.GetDataAll())
+			}
+		})
+	}
+}
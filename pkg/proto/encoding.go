@@ -0,0 +1,176 @@
+package proto
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/heartbytenet/bblib/containers/sync"
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	EncodingIdentity = "identity"
+	EncodingGzip     = "gzip"
+	EncodingZstd     = "zstd"
+)
+
+var (
+	ErrEncodingUnsupported = errors.New("unsupported content encoding")
+	ErrEncodingLimit       = errors.New("decompressed payload exceeds limit")
+
+	Encodings = []string{EncodingZstd, EncodingGzip}
+
+	zstdEncoder, _ = zstd.NewWriter(nil)
+	zstdDecoders   = sync.NewLocked(map[int64]*zstd.Decoder{})
+)
+
+func GetZstdDecoder(limit int64) (decoder *zstd.Decoder, err error) {
+	var (
+		options []zstd.DOption
+	)
+
+	if limit > 0 {
+		options = append(options, zstd.WithDecoderMaxMemory(uint64(limit)))
+	} else {
+		limit = 0
+	}
+
+	zstdDecoders.Map(func(data map[int64]*zstd.Decoder) map[int64]*zstd.Decoder {
+		decoder = data[limit]
+		if decoder != nil {
+			return data
+		}
+
+		decoder, err = zstd.NewReader(nil, options...)
+		if err != nil {
+			return data
+		}
+
+		data[limit] = decoder
+		return data
+	})
+
+	return
+}
+
+func Compress(encoding string, data []byte) (result []byte, err error) {
+	var (
+		buffer bytes.Buffer
+		writer *gzip.Writer
+	)
+
+	switch encoding {
+	case "", EncodingIdentity:
+		return data, nil
+
+	case EncodingGzip:
+		writer = gzip.NewWriter(&buffer)
+
+		_, err = writer.Write(data)
+		if err != nil {
+			return
+		}
+
+		err = writer.Close()
+		if err != nil {
+			return
+		}
+
+		return buffer.Bytes(), nil
+
+	case EncodingZstd:
+		return zstdEncoder.EncodeAll(data, nil), nil
+
+	default:
+		return nil, ErrEncodingUnsupported
+	}
+}
+
+func Decompress(encoding string, data []byte, limit int64) (result []byte, err error) {
+	var (
+		reader io.Reader
+	)
+
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "", EncodingIdentity:
+		return data, nil
+
+	case EncodingGzip:
+		var gzipReader *gzip.Reader
+
+		gzipReader, err = gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return
+		}
+		defer gzipReader.Close()
+
+		reader = gzipReader
+
+	case EncodingZstd:
+		var zstdDecoder *zstd.Decoder
+
+		zstdDecoder, err = GetZstdDecoder(limit)
+		if err != nil {
+			return
+		}
+
+		result, err = zstdDecoder.DecodeAll(data, nil)
+		if errors.Is(err, zstd.ErrDecoderSizeExceeded) {
+			return nil, ErrEncodingLimit
+		}
+
+		return
+
+	default:
+		return nil, ErrEncodingUnsupported
+	}
+
+	if limit > 0 {
+		reader = io.LimitReader(reader, limit+1)
+	}
+
+	result, err = io.ReadAll(reader)
+	if err != nil {
+		return
+	}
+
+	if limit > 0 && int64(len(result)) > limit {
+		return nil, ErrEncodingLimit
+	}
+
+	return
+}
+
+func SelectEncoding(accept string, supported []string) string {
+	var (
+		selected string
+		quality  float64
+	)
+
+	for _, part := range strings.Split(accept, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+
+		value := 1.0
+		if q, flag := strings.CutPrefix(strings.TrimSpace(params), "q="); flag {
+			parsed, err := strconv.ParseFloat(q, 64)
+			if err == nil {
+				value = parsed
+			}
+		}
+
+		for _, encoding := range supported {
+			if name != encoding || value <= quality {
+				continue
+			}
+
+			selected, quality = encoding, value
+		}
+	}
+
+	return selected
+}
@@ -0,0 +1,75 @@
+package proto
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestEncodingRoundTrip(t *testing.T) {
+	payload := bytes.Repeat([]byte("lerpc "), 1024)
+
+	for _, encoding := range append([]string{EncodingIdentity}, Encodings...) {
+		t.Run(encoding, func(t *testing.T) {
+			data, err := Compress(encoding, payload)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := Decompress(encoding, data, int64(len(payload)))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(result, payload) {
+				t.Fatal("payload changed after round trip")
+			}
+		})
+	}
+}
+
+func TestEncodingLimit(t *testing.T) {
+	payload := bytes.Repeat([]byte{0}, 1<<20)
+
+	for _, encoding := range Encodings {
+		t.Run(encoding, func(t *testing.T) {
+			data, err := Compress(encoding, payload)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = Decompress(encoding, data, 1024)
+			if !errors.Is(err, ErrEncodingLimit) {
+				t.Fatalf("expected limit error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestEncodingUnsupported(t *testing.T) {
+	_, err := Decompress("br", []byte{1, 2, 3}, 0)
+	if !errors.Is(err, ErrEncodingUnsupported) {
+		t.Fatalf("expected unsupported error, got %v", err)
+	}
+}
+
+func TestSelectEncoding(t *testing.T) {
+	cases := map[string]string{
+		"":                       "",
+		"br":                     "",
+		"gzip":                   EncodingGzip,
+		"gzip, zstd":             EncodingGzip,
+		"zstd;q=0.5, gzip;q=0.8": EncodingGzip,
+		"zstd;q=0, gzip":         EncodingGzip,
+	}
+
+	if selected := SelectEncoding("gzip", nil); selected != "" {
+		t.Fatalf("selected %q with no supported encodings", selected)
+	}
+
+	for accept, expected := range cases {
+		if selected := SelectEncoding(accept, Encodings); selected != expected {
+			t.Fatalf("accept %q selected %q, expected %q", accept, selected, expected)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package proto
+
+type FrameKind uint8
+
+const (
+	FrameKindMessage FrameKind = iota
+	FrameKindTransferRequest
+	FrameKindTransferStart
+	FrameKindTransferAck
+	FrameKindTransferEnd
+	FrameKindTransferError
+	FrameKindNotify
+	FrameKindSubscribe
+	FrameKindUnsubscribe
+	FrameKindCancel
+	FrameKindStreamData
+	FrameKindStreamEnd
+	FrameKindStreamError
+	FrameKindHello
+	FrameKindStreamAck
+)
+
+type FrameHeader struct {
+	Kind FrameKind `json:"f"`
+}
+
+type Frame struct {
+	Kind    FrameKind      `json:"f"`
+	Key     string         `json:"k,omitempty"`
+	Token   string         `json:"t,omitempty"`
+	Topic   string         `json:"o,omitempty"`
+	Url     string         `json:"u,omitempty"`
+	Seq     uint32         `json:"s,omitempty"`
+	Size    int64          `json:"z,omitempty"`
+	Data    map[string]any `json:"d,omitempty"`
+	Message string         `json:"m,omitempty"`
+}
+
+func NewFrame(kind FrameKind) Frame {
+	return Frame{
+		Kind: kind,
+	}
+}
+
+func (frame Frame) WithKey(value string) Frame {
+	frame.Key = value
+
+	return frame
+}
+
+func (frame Frame) WithToken(value string) Frame {
+	frame.Token = value
+
+	return frame
+}
+
+func (frame Frame) WithTopic(value string) Frame {
+	frame.Topic = value
+
+	return frame
+}
+
+func (frame Frame) WithUrl(value string) Frame {
+	frame.Url = value
+
+	return frame
+}
+
+func (frame Frame) WithSeq(value uint32) Frame {
+	frame.Seq = value
+
+	return frame
+}
+
+func (frame Frame) WithSize(value int64) Frame {
+	frame.Size = value
+
+	return frame
+}
+
+func (frame Frame) WithData(value map[string]any) Frame {
+	frame.Data = value
+
+	return frame
+}
+
+func (frame Frame) WithMessage(value string) Frame {
+	frame.Message = value
+
+	return frame
+}
+
+func (frame Frame) GetKind() FrameKind {
+	return frame.Kind
+}
+
+func (frame Frame) GetKey() string {
+	return frame.Key
+}
+
+func (frame Frame) GetToken() string {
+	return frame.Token
+}
+
+func (frame Frame) GetTopic() string {
+	return frame.Topic
+}
+
+func (frame Frame) GetUrl() string {
+	return frame.Url
+}
+
+func (frame Frame) GetSeq() uint32 {
+	return frame.Seq
+}
+
+func (frame Frame) GetSize() int64 {
+	return frame.Size
+}
+
+func (frame Frame) GetDataAll() map[string]any {
+	return frame.Data
+}
+
+func (frame Frame) GetMessage() string {
+	return frame.Message
+}
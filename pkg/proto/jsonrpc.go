@@ -0,0 +1,180 @@
+package proto
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-json"
+)
+
+const (
+	JsonRpcVersion = "2.0"
+
+	JsonRpcCodeParseError     = -32700
+	JsonRpcCodeInvalidRequest = -32600
+	JsonRpcCodeMethodNotFound = -32601
+	JsonRpcCodeInvalidParams  = -32602
+	JsonRpcCodeInternalError  = -32603
+	JsonRpcCodeServerError    = -32000
+
+	JsonRpcCodeAuthFailed         = -32001
+	JsonRpcCodeVersionUnsupported = -32002
+)
+
+var (
+	ErrJsonRpcVersion = errors.New("unsupported jsonrpc version")
+	ErrJsonRpcMethod  = errors.New("method must be of the form namespace.method")
+	ErrJsonRpcParams  = errors.New("params must be an object")
+	ErrJsonRpcId      = errors.New("id must be a string, number or null")
+)
+
+type JsonRpcRequest struct {
+	Version string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Id      json.RawMessage `json:"id,omitempty"`
+}
+
+type JsonRpcResponse struct {
+	Version string          `json:"jsonrpc"`
+	Result  any             `json:"result,omitempty"`
+	Error   *JsonRpcError   `json:"error,omitempty"`
+	Id      json.RawMessage `json:"id"`
+}
+
+type JsonRpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+func NewJsonRpcError(code int, message string) *JsonRpcError {
+	return &JsonRpcError{
+		Code:    code,
+		Message: message,
+	}
+}
+
+func NewJsonRpcResponse(id json.RawMessage) JsonRpcResponse {
+	if len(id) < 1 {
+		id = json.RawMessage("null")
+	}
+
+	return JsonRpcResponse{
+		Version: JsonRpcVersion,
+		Id:      id,
+	}
+}
+
+func (response JsonRpcResponse) WithResult(value any) JsonRpcResponse {
+	response.Result = value
+	response.Error = nil
+
+	return response
+}
+
+func (response JsonRpcResponse) WithError(value *JsonRpcError) JsonRpcResponse {
+	response.Result = nil
+	response.Error = value
+
+	return response
+}
+
+func (request JsonRpcRequest) IsNotification() bool {
+	return len(request.Id) < 1
+}
+
+func (request JsonRpcRequest) GetKey() (key string, err error) {
+	var (
+		decoder *json.Decoder
+		value   any
+	)
+
+	if len(request.Id) < 1 {
+		return
+	}
+
+	decoder = json.NewDecoder(bytes.NewReader(request.Id))
+	decoder.UseNumber()
+
+	err = decoder.Decode(&value)
+	if err != nil {
+		err = ErrJsonRpcId
+		return
+	}
+
+	switch value := value.(type) {
+	case nil:
+		return
+
+	case string:
+		key = value
+		return
+
+	case json.Number:
+		integer, failure := value.Int64()
+		if failure == nil {
+			key = strconv.FormatInt(integer, 10)
+			return
+		}
+
+		float, failure := value.Float64()
+		if failure == nil {
+			key = strconv.FormatFloat(float, 'g', -1, 64)
+			return
+		}
+
+		key = value.String()
+		return
+
+	default:
+		err = ErrJsonRpcId
+		return
+	}
+}
+
+func (request JsonRpcRequest) ToRequest(token string) (result Request, err error) {
+	var (
+		namespace string
+		method    string
+		params    map[string]any
+		key       string
+		flag      bool
+	)
+
+	if request.Version != JsonRpcVersion {
+		err = ErrJsonRpcVersion
+		return
+	}
+
+	namespace, method, flag = strings.Cut(request.Method, ".")
+	if !flag || namespace == "" || method == "" {
+		err = ErrJsonRpcMethod
+		return
+	}
+
+	key, err = request.GetKey()
+	if err != nil {
+		return
+	}
+
+	if len(request.Params) > 0 && string(request.Params) != "null" {
+		err = json.Unmarshal(request.Params, &params)
+		if err != nil {
+			err = ErrJsonRpcParams
+			return
+		}
+	}
+
+	result = NewRequest().
+		WithToken(token).
+		WithKey(key).
+		WithNamespace(namespace).
+		WithMethod(method).
+		WithParams(params).
+		WithNoReply(request.IsNotification())
+
+	return
+}
@@ -0,0 +1,54 @@
+package proto
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/goccy/go-json"
+)
+
+func TestJsonRpcRequestKey(t *testing.T) {
+	cases := map[string]string{
+		``:      "",
+		`null`:  "",
+		`"abc"`: "abc",
+		`"7"`:   "7",
+		`7`:     "7",
+		`7.0`:   "7",
+		` 7 `:   "7",
+		`1.5`:   "1.5",
+	}
+
+	for id, expected := range cases {
+		key, err := JsonRpcRequest{Id: json.RawMessage(id)}.GetKey()
+		if err != nil {
+			t.Fatalf("%q: %v", id, err)
+		}
+
+		if key != expected {
+			t.Fatalf("%q produced key %q, expected %q", id, key, expected)
+		}
+	}
+
+	for _, id := range []string{`{}`, `[1]`, `true`} {
+		_, err := JsonRpcRequest{Id: json.RawMessage(id)}.GetKey()
+		if !errors.Is(err, ErrJsonRpcId) {
+			t.Fatalf("%q: expected invalid id, got %v", id, err)
+		}
+	}
+}
+
+func TestJsonRpcToRequestKey(t *testing.T) {
+	request, err := JsonRpcRequest{
+		Version: JsonRpcVersion,
+		Method:  "a.b",
+		Id:      json.RawMessage(`"abc"`),
+	}.ToRequest("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if request.GetKey() != "abc" {
+		t.Fatalf("unexpected key %q", request.GetKey())
+	}
+}
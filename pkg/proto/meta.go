@@ -0,0 +1,51 @@
+package proto
+
+import (
+	"strings"
+
+	"github.com/heartbytenet/bblib/containers/optionals"
+)
+
+func NormalizeMetaKey(key string) string {
+	return strings.ToLower(strings.TrimSpace(key))
+}
+
+func NormalizeMeta(meta map[string]string) map[string]string {
+	if meta == nil {
+		return nil
+	}
+
+	result := make(map[string]string, len(meta))
+	for key, value := range meta {
+		result[NormalizeMetaKey(key)] = value
+	}
+
+	return result
+}
+
+func CopyMetaWith(meta map[string]string, key string, value string) map[string]string {
+	result := make(map[string]string, len(meta)+1)
+	for k, v := range meta {
+		result[NormalizeMetaKey(k)] = v
+	}
+
+	result[NormalizeMetaKey(key)] = value
+	return result
+}
+
+func LookupMeta(meta map[string]string, key string) optionals.Optional[string] {
+	key = NormalizeMetaKey(key)
+
+	value, flag := meta[key]
+	if flag {
+		return optionals.Some(value)
+	}
+
+	for k, v := range meta {
+		if strings.EqualFold(k, key) {
+			return optionals.Some(v)
+		}
+	}
+
+	return optionals.None[string]()
+}
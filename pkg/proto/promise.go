@@ -2,11 +2,19 @@ package proto
 
 import (
 	"context"
+	"errors"
+	"sync"
+)
+
+var (
+	ErrCancelled   = errors.New("request cancelled")
+	ErrKeyInFlight = errors.New("request key already in flight")
 )
 
 type Promise[T any] struct {
 	ctx    context.Context
 	cancel context.CancelFunc
+	once   sync.Once
 	value  T
 	err    error
 }
@@ -22,13 +30,17 @@ func NewPromise[T any]() *Promise[T] {
 }
 
 func (promise *Promise[T]) Complete(value T) {
-	promise.value = value
-	promise.cancel()
+	promise.once.Do(func() {
+		promise.value = value
+		promise.cancel()
+	})
 }
 
 func (promise *Promise[T]) Failed(err error) {
-	promise.err = err
-	promise.cancel()
+	promise.once.Do(func() {
+		promise.err = err
+		promise.cancel()
+	})
 }
 
 func (promise *Promise[T]) Await() (T, error) {
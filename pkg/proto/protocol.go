@@ -0,0 +1,11 @@
+package proto
+
+const (
+	Subprotocol        = "lerpc.v1"
+	SubprotocolJsonRpc = "jsonrpc.v2"
+)
+
+const (
+	HeaderMetaPrefix = "Lerpc-Meta-"
+	HeaderVersion    = "Lerpc-Version"
+)
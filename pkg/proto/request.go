@@ -6,11 +6,15 @@ import (
 )
 
 type Request struct {
-	Token     string         `json:"t,omitempty"`
-	Key       string         `json:"k,omitempty"`
-	Namespace string         `json:"n,omitempty"`
-	Method    string         `json:"m,omitempty"`
-	Params    map[string]any `json:"p,omitempty"`
+	Token     string            `json:"t,omitempty"`
+	Key       string            `json:"k,omitempty"`
+	Namespace string            `json:"n,omitempty"`
+	Method    string            `json:"m,omitempty"`
+	Params    map[string]any    `json:"p,omitempty"`
+	Stream    bool              `json:"s,omitempty"`
+	NoReply   bool              `json:"r,omitempty"`
+	Meta      map[string]string `json:"x,omitempty"`
+	Version   uint32            `json:"v,omitempty"`
 }
 
 func NewRequest() Request {
@@ -47,6 +51,44 @@ func (request Request) WithParams(value map[string]any) Request {
 	return request
 }
 
+func (request Request) WithStream(value bool) Request {
+	request.Stream = value
+
+	return request
+}
+
+func (request Request) WithNoReply(value bool) Request {
+	request.NoReply = value
+
+	return request
+}
+
+func (request Request) WithVersion(value uint32) Request {
+	request.Version = value
+
+	return request
+}
+
+func (request Request) WithMeta(value map[string]string) Request {
+	request.Meta = NormalizeMeta(value)
+
+	return request
+}
+
+func (request Request) SetMeta(key string, value string) Request {
+	request.Meta = CopyMetaWith(request.Meta, key, value)
+
+	return request
+}
+
+func (request Request) GetMeta(key string) optionals.Optional[string] {
+	return LookupMeta(request.Meta, key)
+}
+
+func (request Request) GetMetaAll() map[string]string {
+	return request.Meta
+}
+
 func (request Request) SetParam(key string, value any) Request {
 	if request.Params == nil {
 		request.Params = map[string]any{}
@@ -117,3 +159,15 @@ func (request Request) GetNamespace() string {
 func (request Request) GetMethod() string {
 	return request.Method
 }
+
+func (request Request) GetStream() bool {
+	return request.Stream
+}
+
+func (request Request) GetNoReply() bool {
+	return request.NoReply
+}
+
+func (request Request) GetVersion() uint32 {
+	return request.Version
+}
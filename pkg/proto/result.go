@@ -7,10 +7,11 @@ import (
 )
 
 type Result struct {
-	Key     string         `json:"k,omitempty"`
-	Code    ResultCode     `json:"c"`
-	Data    map[string]any `json:"d"`
-	Message string         `json:"m,omitempty"`
+	Key     string            `json:"k,omitempty"`
+	Code    ResultCode        `json:"c"`
+	Data    map[string]any    `json:"d"`
+	Message string            `json:"m,omitempty"`
+	Meta    map[string]string `json:"x,omitempty"`
 }
 
 func NewResult() Result {
@@ -41,6 +42,18 @@ func (result Result) WithMessage(value string) Result {
 	return result
 }
 
+func (result Result) WithMeta(value map[string]string) Result {
+	result.Meta = NormalizeMeta(value)
+
+	return result
+}
+
+func (result Result) SetMeta(key string, value string) Result {
+	result.Meta = CopyMetaWith(result.Meta, key, value)
+
+	return result
+}
+
 func (result Result) SetData(key string, value any) Result {
 	if result.Data == nil {
 		result.Data = map[string]any{}
@@ -72,6 +85,14 @@ func (result Result) GetDataAll() map[string]any {
 	return result.Data
 }
 
+func (result Result) GetMeta(key string) optionals.Optional[string] {
+	return LookupMeta(result.Meta, key)
+}
+
+func (result Result) GetMetaAll() map[string]string {
+	return result.Meta
+}
+
 func GetDataValue[T any](result Result, key string) (value optionals.Optional[T]) {
 	return optionals.FlatMap[any, T](
 		result.GetData(key),
@@ -0,0 +1,83 @@
+package proto
+
+import (
+	"strings"
+)
+
+const (
+	TopicSeparator      = "."
+	TopicWildcardSingle = "*"
+	TopicWildcardTail   = ">"
+)
+
+func MatchTopic(pattern string, topic string) bool {
+	var (
+		patternParts []string
+		topicParts   []string
+	)
+
+	patternParts = strings.Split(pattern, TopicSeparator)
+	topicParts = strings.Split(topic, TopicSeparator)
+
+	for index, part := range patternParts {
+		if part == TopicWildcardTail {
+			return index == len(patternParts)-1 && len(topicParts) > index
+		}
+
+		if index >= len(topicParts) {
+			return false
+		}
+
+		if part == TopicWildcardSingle {
+			continue
+		}
+
+		if part != topicParts[index] {
+			return false
+		}
+	}
+
+	return len(patternParts) == len(topicParts)
+}
+
+func CoverTopic(pattern string, subscription string) bool {
+	var (
+		patternParts      []string
+		subscriptionParts []string
+	)
+
+	patternParts = strings.Split(pattern, TopicSeparator)
+	subscriptionParts = strings.Split(subscription, TopicSeparator)
+
+	for index, part := range patternParts {
+		if part == TopicWildcardTail {
+			return index == len(patternParts)-1 && len(subscriptionParts) > index
+		}
+
+		if index >= len(subscriptionParts) {
+			return false
+		}
+
+		switch subscriptionParts[index] {
+		case TopicWildcardTail:
+			return false
+
+		case TopicWildcardSingle:
+			if part != TopicWildcardSingle {
+				return false
+			}
+
+			continue
+		}
+
+		if part == TopicWildcardSingle {
+			continue
+		}
+
+		if part != subscriptionParts[index] {
+			return false
+		}
+	}
+
+	return len(patternParts) == len(subscriptionParts)
+}
@@ -0,0 +1,56 @@
+package proto
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+const (
+	ChunkMarker byte = 0x00
+)
+
+type Chunk struct {
+	Key  string
+	Seq  uint32
+	Data []byte
+}
+
+func EncodeChunk(key string, seq uint32, data []byte) (buffer []byte, err error) {
+	if len(key) > 0xff {
+		err = fmt.Errorf("chunk key too long: %d", len(key))
+		return
+	}
+
+	buffer = make([]byte, 0, 2+len(key)+4+len(data))
+	buffer = append(buffer, ChunkMarker, byte(len(key)))
+	buffer = append(buffer, key...)
+	buffer = binary.BigEndian.AppendUint32(buffer, seq)
+	buffer = append(buffer, data...)
+
+	return
+}
+
+func DecodeChunk(buffer []byte) (chunk Chunk, err error) {
+	var (
+		size int
+	)
+
+	if len(buffer) < 2 || buffer[0] != ChunkMarker {
+		err = fmt.Errorf("invalid chunk header")
+		return
+	}
+
+	size = int(buffer[1])
+	if len(buffer) < 2+size+4 {
+		err = fmt.Errorf("invalid chunk length: %d", len(buffer))
+		return
+	}
+
+	chunk = Chunk{
+		Key:  string(buffer[2 : 2+size]),
+		Seq:  binary.BigEndian.Uint32(buffer[2+size:]),
+		Data: buffer[2+size+4:],
+	}
+
+	return
+}
@@ -0,0 +1,52 @@
+package proto
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+const (
+	HeaderUploadOffset   = "Upload-Offset"
+	HeaderUploadLength   = "Upload-Length"
+	HeaderUploadChecksum = "Upload-Checksum"
+	HeaderUploadDigest   = "Upload-Digest"
+
+	ContentTypeUpload = "application/offset+octet-stream"
+
+	UploadChecksumAlgorithm = "sha256"
+)
+
+func FormatUploadChecksum(sum []byte) string {
+	return fmt.Sprintf("%s %s", UploadChecksumAlgorithm, base64.StdEncoding.EncodeToString(sum))
+}
+
+func ParseUploadChecksum(value string) (sum []byte, err error) {
+	var (
+		algorithm string
+		encoded   string
+		flag      bool
+	)
+
+	if value == "" {
+		return
+	}
+
+	algorithm, encoded, flag = strings.Cut(value, " ")
+	if !flag {
+		err = fmt.Errorf("invalid checksum header: %s", value)
+		return
+	}
+
+	if algorithm != UploadChecksumAlgorithm {
+		err = fmt.Errorf("unsupported checksum algorithm: %s", algorithm)
+		return
+	}
+
+	sum, err = base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return
+	}
+
+	return
+}
@@ -0,0 +1,180 @@
+package proto
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-json"
+)
+
+const (
+	ProtocolVersion    uint32 = 1
+	ProtocolVersionMin uint32 = 1
+
+	FeatureBatch    = "batch"
+	FeatureStream   = "stream"
+	FeatureNoReply  = "noreply"
+	FeatureMeta     = "meta"
+	FeatureEvents   = "events"
+	FeatureTransfer = "transfer"
+	FeatureUpload   = "upload"
+	FeatureJsonRpc  = "jsonrpc"
+)
+
+var (
+	ErrVersionUnsupported = errors.New("unsupported protocol version")
+
+	Features = []string{
+		FeatureBatch,
+		FeatureStream,
+		FeatureNoReply,
+		FeatureMeta,
+		FeatureEvents,
+		FeatureTransfer,
+		FeatureUpload,
+		FeatureJsonRpc,
+	}
+)
+
+type Capabilities struct {
+	Version      uint32   `json:"v"`
+	VersionMin   uint32   `json:"vm,omitempty"`
+	Codecs       []string `json:"c,omitempty"`
+	Encodings    []string `json:"e,omitempty"`
+	Subprotocols []string `json:"s,omitempty"`
+	Features     []string `json:"f,omitempty"`
+	Negotiated   uint32   `json:"n,omitempty"`
+}
+
+func NewCapabilities() Capabilities {
+	codecs := make([]string, 0, len(Codecs))
+	for _, codec := range Codecs {
+		codecs = append(codecs, codec.GetName())
+	}
+
+	return Capabilities{
+		Version:    ProtocolVersion,
+		VersionMin: ProtocolVersionMin,
+		Codecs:     codecs,
+		Encodings:  slices.Clone(Encodings),
+		Features:   slices.Clone(Features),
+	}
+}
+
+func CapabilitiesFromData(data map[string]any) (capabilities Capabilities, err error) {
+	var (
+		value []byte
+	)
+
+	value, err = json.Marshal(data)
+	if err != nil {
+		return
+	}
+
+	err = json.Unmarshal(value, &capabilities)
+	return
+}
+
+func (capabilities Capabilities) ToData() (data map[string]any) {
+	value, err := json.Marshal(capabilities)
+	if err != nil {
+		return
+	}
+
+	_ = json.Unmarshal(value, &data)
+	return
+}
+
+func (capabilities Capabilities) HasCodec(name string) bool {
+	return slices.Contains(capabilities.Codecs, name)
+}
+
+func (capabilities Capabilities) HasEncoding(name string) bool {
+	return slices.Contains(capabilities.Encodings, name)
+}
+
+func (capabilities Capabilities) HasFeature(name string) bool {
+	return slices.Contains(capabilities.Features, name)
+}
+
+func VersionRange() string {
+	return FormatVersionRange(ProtocolVersionMin, ProtocolVersion)
+}
+
+func FormatVersionRange(low uint32, high uint32) string {
+	if low == 0 || low == high {
+		return strconv.FormatUint(uint64(high), 10)
+	}
+
+	return strconv.FormatUint(uint64(low), 10) + "-" + strconv.FormatUint(uint64(high), 10)
+}
+
+func ParseVersionRange(value string) (low uint32, high uint32, err error) {
+	var (
+		number uint64
+	)
+
+	first, second, flag := strings.Cut(strings.TrimSpace(value), "-")
+
+	number, err = strconv.ParseUint(strings.TrimSpace(first), 10, 32)
+	if err != nil {
+		err = fmt.Errorf("%w: invalid version %q", ErrVersionUnsupported, value)
+		return
+	}
+
+	low, high = uint32(number), uint32(number)
+	if !flag {
+		return
+	}
+
+	number, err = strconv.ParseUint(strings.TrimSpace(second), 10, 32)
+	if err != nil || uint32(number) < low {
+		err = fmt.Errorf("%w: invalid version %q", ErrVersionUnsupported, value)
+		return
+	}
+
+	high = uint32(number)
+	return
+}
+
+func SelectVersion(low uint32, high uint32, otherLow uint32, otherHigh uint32) (version uint32, err error) {
+	if low == 0 {
+		low = high
+	}
+
+	if otherLow == 0 {
+		otherLow = otherHigh
+	}
+
+	version = min(high, otherHigh)
+	if version < low || version < otherLow {
+		return 0, fmt.Errorf("%w: %s, supported versions are %s",
+			ErrVersionUnsupported, FormatVersionRange(low, high), FormatVersionRange(otherLow, otherHigh))
+	}
+
+	return
+}
+
+func NegotiateVersion(low uint32, high uint32) (uint32, error) {
+	if high == 0 {
+		return 0, nil
+	}
+
+	return SelectVersion(low, high, ProtocolVersionMin, ProtocolVersion)
+}
+
+func CheckVersion(version uint32) error {
+	if version == 0 {
+		return nil
+	}
+
+	if version < ProtocolVersionMin || version > ProtocolVersion {
+		return fmt.Errorf("%w: %d, supported versions are %d to %d",
+			ErrVersionUnsupported, version, ProtocolVersionMin, ProtocolVersion)
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package proto
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseVersionRange(t *testing.T) {
+	cases := map[string][2]uint32{
+		"1":     {1, 1},
+		"1-2":   {1, 2},
+		" 2-4 ": {2, 4},
+	}
+
+	for value, expected := range cases {
+		low, high, err := ParseVersionRange(value)
+		if err != nil {
+			t.Fatalf("%q: %v", value, err)
+		}
+
+		if low != expected[0] || high != expected[1] {
+			t.Fatalf("%q parsed as %d-%d", value, low, high)
+		}
+	}
+
+	for _, value := range []string{"", "x", "2-1", "1-x"} {
+		_, _, err := ParseVersionRange(value)
+		if !errors.Is(err, ErrVersionUnsupported) {
+			t.Fatalf("%q: expected unsupported version, got %v", value, err)
+		}
+	}
+}
+
+func TestFormatVersionRange(t *testing.T) {
+	if value := FormatVersionRange(1, 1); value != "1" {
+		t.Fatalf("unexpected range %q", value)
+	}
+
+	if value := FormatVersionRange(1, 3); value != "1-3" {
+		t.Fatalf("unexpected range %q", value)
+	}
+}
+
+func TestSelectVersion(t *testing.T) {
+	version, err := SelectVersion(1, 2, 1, 1)
+	if err != nil || version != 1 {
+		t.Fatalf("newer client against older server selected %d: %v", version, err)
+	}
+
+	version, err = SelectVersion(1, 1, 1, 3)
+	if err != nil || version != 1 {
+		t.Fatalf("older client against newer server selected %d: %v", version, err)
+	}
+
+	version, err = SelectVersion(2, 3, 1, 4)
+	if err != nil || version != 3 {
+		t.Fatalf("overlapping ranges selected %d: %v", version, err)
+	}
+
+	_, err = SelectVersion(2, 3, 1, 1)
+	if !errors.Is(err, ErrVersionUnsupported) {
+		t.Fatalf("expected unsupported version, got %v", err)
+	}
+}
+
+func TestNegotiateVersion(t *testing.T) {
+	version, err := NegotiateVersion(0, 0)
+	if err != nil || version != 0 {
+		t.Fatalf("legacy peer selected %d: %v", version, err)
+	}
+
+	version, err = NegotiateVersion(ProtocolVersionMin, ProtocolVersion+1)
+	if err != nil || version != ProtocolVersion {
+		t.Fatalf("newer peer selected %d: %v", version, err)
+	}
+
+	_, err = NegotiateVersion(ProtocolVersion+1, ProtocolVersion+2)
+	if !errors.Is(err, ErrVersionUnsupported) {
+		t.Fatalf("expected unsupported version, got %v", err)
+	}
+}
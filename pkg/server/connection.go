@@ -0,0 +1,443 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/heartbytenet/bblib/collections/generic"
+	"github.com/heartbytenet/bblib/containers/optionals"
+	"github.com/heartbytenet/bblib/containers/sync"
+	"github.com/heartbytenet/go-lerpc/pkg/proto"
+)
+
+var (
+	ErrConnectionClosed = errors.New("connection closed")
+	ErrSlowConsumer     = errors.New("connection send buffer is full")
+)
+
+type Connection struct {
+	id         string
+	remoteAddr string
+	created    time.Time
+	principal  *sync.Locked[string]
+	session    *Session
+
+	conn     *websocket.Conn
+	codec    proto.Codec
+	ctx      context.Context
+	cancel   context.CancelFunc
+	outgoing chan generic.Pair[int, []byte]
+
+	slowPolicy  SlowConsumerPolicy
+	slowTimeout time.Duration
+
+	pingInterval time.Duration
+	pongTimeout  time.Duration
+	writeTimeout time.Duration
+	idleTimeout  time.Duration
+	idleTimer    *time.Timer
+
+	transfers *sync.Locked[map[string]*Transfer]
+	streams   *sync.Locked[map[string]*Stream]
+	keys      *sync.Locked[map[string]struct{}]
+}
+
+func NewConnection(conn *websocket.Conn, remoteAddr string, settings Settings) *Connection {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Connection{
+		id:         NewConnectionId(),
+		remoteAddr: remoteAddr,
+		created:    time.Now(),
+		principal:  sync.NewLocked(""),
+		session:    NewSession(),
+
+		conn:     conn,
+		codec:    proto.GetCodecBySubprotocol(conn.Subprotocol()).GetDefault(proto.CodecDefault),
+		ctx:      ctx,
+		cancel:   cancel,
+		outgoing: make(chan generic.Pair[int, []byte], settings.ConnectionBuffer),
+
+		slowPolicy:  settings.SlowConsumerPolicy,
+		slowTimeout: settings.SlowConsumerTimeout,
+
+		pingInterval: settings.PingInterval,
+		pongTimeout:  settings.PongTimeout,
+		writeTimeout: settings.WriteTimeout,
+		idleTimeout:  settings.IdleTimeout,
+
+		transfers: sync.NewLocked(map[string]*Transfer{}),
+		streams:   sync.NewLocked(map[string]*Stream{}),
+		keys:      sync.NewLocked(map[string]struct{}{}),
+	}
+}
+
+func NewConnectionId() string {
+	data := make([]byte, 12)
+
+	_, err := rand.Read(data)
+	if err != nil {
+		panic(err)
+	}
+
+	return hex.EncodeToString(data)
+}
+
+func (connection *Connection) GetId() string {
+	return connection.id
+}
+
+func (connection *Connection) GetRemoteAddr() string {
+	return connection.remoteAddr
+}
+
+func (connection *Connection) GetCreated() time.Time {
+	return connection.created
+}
+
+func (connection *Connection) GetPrincipal() string {
+	return connection.principal.Get()
+}
+
+func (connection *Connection) SetPrincipal(principal string) {
+	connection.principal.Set(principal)
+}
+
+func (connection *Connection) GetSession() *Session {
+	return connection.session
+}
+
+func (connection *Connection) GetConn() *websocket.Conn {
+	return connection.conn
+}
+
+func (connection *Connection) GetCodec() proto.Codec {
+	return connection.codec
+}
+
+func (connection *Connection) GetContext() context.Context {
+	return connection.ctx
+}
+
+func (connection *Connection) GetOutgoing() chan generic.Pair[int, []byte] {
+	return connection.outgoing
+}
+
+func (connection *Connection) Close() {
+	connection.cancel()
+}
+
+func (connection *Connection) Kick() (err error) {
+	connection.Close()
+
+	_ = connection.conn.WriteControl(
+		websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "kicked"),
+		connection.deadline())
+
+	return connection.conn.Close()
+}
+
+func (connection *Connection) Send(kind int, data []byte) (err error) {
+	var (
+		msg   generic.Pair[int, []byte]
+		timer *time.Timer
+	)
+
+	msg = generic.NewPair(kind, data)
+
+	select {
+	case <-connection.ctx.Done():
+		return ErrConnectionClosed
+
+	case connection.outgoing <- msg:
+		return nil
+
+	default:
+		break
+	}
+
+	switch connection.slowPolicy {
+	case SlowConsumerDrop:
+		return ErrSlowConsumer
+
+	case SlowConsumerDisconnect:
+		_ = connection.Kick()
+		return ErrSlowConsumer
+
+	default:
+		if connection.slowTimeout <= 0 {
+			select {
+			case <-connection.ctx.Done():
+				return ErrConnectionClosed
+
+			case connection.outgoing <- msg:
+				return nil
+			}
+		}
+
+		timer = time.NewTimer(connection.slowTimeout)
+		defer timer.Stop()
+
+		select {
+		case <-connection.ctx.Done():
+			return ErrConnectionClosed
+
+		case connection.outgoing <- msg:
+			return nil
+
+		case <-timer.C:
+			_ = connection.Kick()
+			return ErrSlowConsumer
+		}
+	}
+}
+
+func (connection *Connection) StartKeepalive(maxMessageSize int64) {
+	if maxMessageSize > 0 {
+		connection.conn.SetReadLimit(maxMessageSize)
+	}
+
+	if connection.pongTimeout > 0 {
+		_ = connection.conn.SetReadDeadline(time.Now().Add(connection.pongTimeout))
+
+		connection.conn.SetPongHandler(func(string) error {
+			return connection.conn.SetReadDeadline(time.Now().Add(connection.pongTimeout))
+		})
+	}
+
+	if connection.idleTimeout > 0 {
+		connection.idleTimer = time.AfterFunc(connection.idleTimeout, func() {
+			_ = connection.Kick()
+		})
+	}
+}
+
+func (connection *Connection) Touch() {
+	if connection.pongTimeout > 0 {
+		_ = connection.conn.SetReadDeadline(time.Now().Add(connection.pongTimeout))
+	}
+
+	if connection.idleTimer != nil {
+		connection.idleTimer.Reset(connection.idleTimeout)
+	}
+}
+
+func (connection *Connection) StopKeepalive() {
+	if connection.idleTimer != nil {
+		connection.idleTimer.Stop()
+	}
+}
+
+func (connection *Connection) deadline() time.Time {
+	if connection.writeTimeout <= 0 {
+		return time.Time{}
+	}
+
+	return time.Now().Add(connection.writeTimeout)
+}
+
+func (connection *Connection) LoopWrite() {
+	var (
+		ticker *time.Ticker
+		ping   <-chan time.Time
+		err    error
+	)
+
+	if connection.pingInterval > 0 {
+		ticker = time.NewTicker(connection.pingInterval)
+		defer ticker.Stop()
+
+		ping = ticker.C
+	}
+
+	for {
+		select {
+		case <-connection.ctx.Done():
+			return
+
+		case <-ping:
+			err = connection.conn.WriteControl(websocket.PingMessage, nil, connection.deadline())
+			if err != nil {
+				connection.Close()
+				return
+			}
+
+		case msg := <-connection.outgoing:
+			_ = connection.conn.SetWriteDeadline(connection.deadline())
+
+			err = connection.conn.WriteMessage(msg.A(), msg.B())
+			if err != nil {
+				connection.Close()
+				return
+			}
+		}
+	}
+}
+
+func (connection *Connection) Offer(kind int, data []byte) (err error) {
+	if connection.ctx.Err() != nil {
+		return ErrConnectionClosed
+	}
+
+	select {
+	case connection.outgoing <- generic.NewPair(kind, data):
+		return nil
+
+	default:
+		break
+	}
+
+	if connection.slowPolicy == SlowConsumerDisconnect {
+		_ = connection.Kick()
+	}
+
+	return ErrSlowConsumer
+}
+
+func (connection *Connection) encode(value any) (kind int, data []byte, err error) {
+	data, err = connection.codec.Marshal(value)
+	if err != nil {
+		return
+	}
+
+	kind = websocket.TextMessage
+	if connection.codec.IsBinary() {
+		kind = websocket.BinaryMessage
+	}
+
+	return
+}
+
+func (connection *Connection) SendValue(value any) (err error) {
+	var (
+		kind int
+		data []byte
+	)
+
+	kind, data, err = connection.encode(value)
+	if err != nil {
+		return
+	}
+
+	return connection.Send(kind, data)
+}
+
+func (connection *Connection) OfferValue(value any) (err error) {
+	var (
+		kind int
+		data []byte
+	)
+
+	kind, data, err = connection.encode(value)
+	if err != nil {
+		return
+	}
+
+	return connection.Offer(kind, data)
+}
+
+func (connection *Connection) SendFrame(frame proto.Frame) error {
+	return connection.SendValue(frame)
+}
+
+func (connection *Connection) OfferFrame(frame proto.Frame) error {
+	return connection.OfferValue(frame)
+}
+
+func (connection *Connection) Notify(topic string, data map[string]any) error {
+	return connection.OfferFrame(proto.NewFrame(proto.FrameKindNotify).
+		WithTopic(topic).
+		WithData(data))
+}
+
+func (connection *Connection) Deliver(event Event) error {
+	return connection.Notify(event.Topic, event.Data)
+}
+
+func (connection *Connection) AddTransfer(transfer *Transfer) {
+	connection.transfers.Map(func(data map[string]*Transfer) map[string]*Transfer {
+		data[transfer.GetKey()] = transfer
+		return data
+	})
+}
+
+func (connection *Connection) RemoveTransfer(key string) {
+	connection.transfers.Map(func(data map[string]*Transfer) map[string]*Transfer {
+		delete(data, key)
+		return data
+	})
+}
+
+func (connection *Connection) GetTransfer(key string) (result optionals.Optional[*Transfer]) {
+	result = optionals.None[*Transfer]()
+
+	connection.transfers.Apply(func(data map[string]*Transfer) {
+		transfer, flag := data[key]
+		if !flag {
+			return
+		}
+
+		result = optionals.Some(transfer)
+	})
+
+	return
+}
+
+func (connection *Connection) AddStream(stream *Stream) {
+	connection.streams.Map(func(data map[string]*Stream) map[string]*Stream {
+		data[stream.GetKey()] = stream
+		return data
+	})
+}
+
+func (connection *Connection) RemoveStream(key string) {
+	connection.streams.Map(func(data map[string]*Stream) map[string]*Stream {
+		delete(data, key)
+		return data
+	})
+}
+
+func (connection *Connection) GetStream(key string) (result optionals.Optional[*Stream]) {
+	result = optionals.None[*Stream]()
+
+	connection.streams.Apply(func(data map[string]*Stream) {
+		stream, flag := data[key]
+		if !flag {
+			return
+		}
+
+		result = optionals.Some(stream)
+	})
+
+	return
+}
+
+func (connection *Connection) AcquireKey(key string) (flag bool) {
+	if key == "" {
+		return true
+	}
+
+	connection.keys.Map(func(data map[string]struct{}) map[string]struct{} {
+		if _, exists := data[key]; exists {
+			return data
+		}
+
+		data[key] = struct{}{}
+		flag = true
+		return data
+	})
+
+	return
+}
+
+func (connection *Connection) ReleaseKey(key string) {
+	connection.keys.Map(func(data map[string]struct{}) map[string]struct{} {
+		delete(data, key)
+		return data
+	})
+}
@@ -0,0 +1,60 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/heartbytenet/bblib/collections/generic"
+	"github.com/heartbytenet/go-lerpc/pkg/proto"
+)
+
+func testConnection(buffer int, policy SlowConsumerPolicy) *Connection {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Connection{
+		codec:       proto.CodecDefault,
+		ctx:         ctx,
+		cancel:      cancel,
+		outgoing:    make(chan generic.Pair[int, []byte], buffer),
+		slowPolicy:  policy,
+		slowTimeout: time.Minute,
+	}
+}
+
+func TestConnectionNotifyNonBlocking(t *testing.T) {
+	connection := testConnection(1, SlowConsumerBlock)
+
+	if err := connection.Notify("a", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- connection.Notify("b", nil)
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrSlowConsumer) {
+			t.Fatalf("expected slow consumer, got %v", err)
+		}
+
+	case <-time.After(time.Second):
+		t.Fatal("notify blocked on a full send buffer")
+	}
+
+	if connection.ctx.Err() != nil {
+		t.Fatal("dropped notification closed the connection")
+	}
+}
+
+func TestConnectionNotifyClosed(t *testing.T) {
+	connection := testConnection(1, SlowConsumerBlock)
+	connection.Close()
+
+	if err := connection.Notify("a", nil); !errors.Is(err, ErrConnectionClosed) {
+		t.Fatalf("expected closed connection, got %v", err)
+	}
+}
@@ -1,26 +1,48 @@
 package server
 
 import (
+	"context"
+	"errors"
+
 	"github.com/heartbytenet/bblib/collections/generic"
 	"github.com/heartbytenet/bblib/containers/optionals"
 	"github.com/heartbytenet/go-lerpc/pkg/client"
 	"github.com/heartbytenet/go-lerpc/pkg/proto"
 )
 
+var (
+	ErrNoConnection = errors.New("request has no connection")
+)
+
 type RequestContext struct {
 	executor   *Executor
 	clientMode client.ClientMode
-	conn       optionals.Optional[chan generic.Pair[int, []byte]]
+	connection optionals.Optional[*Connection]
 	request    proto.Request
+
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
-func NewRequestContext(executor *Executor, clientMode client.ClientMode, outgoing chan generic.Pair[int, []byte], request proto.Request) *RequestContext {
-	return &RequestContext{
+func NewRequestContext(executor *Executor, clientMode client.ClientMode, connection *Connection, request proto.Request) *RequestContext {
+	request.Meta = proto.NormalizeMeta(request.Meta)
+
+	ctx := &RequestContext{
 		executor:   executor,
 		clientMode: clientMode,
-		conn:       optionals.FromNillable[chan generic.Pair[int, []byte]](outgoing),
+		connection: optionals.None[*Connection](),
 		request:    request,
 	}
+
+	parent := context.Background()
+	if connection != nil {
+		ctx.connection = optionals.Some(connection)
+		parent = connection.GetContext()
+	}
+
+	ctx.ctx, ctx.cancel = context.WithCancel(parent)
+
+	return ctx
 }
 
 func (ctx *RequestContext) GetExecutor() *Executor {
@@ -31,10 +53,87 @@ func (ctx *RequestContext) GetClientMode() client.ClientMode {
 	return ctx.clientMode
 }
 
+func (ctx *RequestContext) GetConnection() optionals.Optional[*Connection] {
+	return ctx.connection
+}
+
 func (ctx *RequestContext) GetConn() optionals.Optional[chan generic.Pair[int, []byte]] {
-	return ctx.conn
+	return optionals.FlatMap(ctx.connection, func(connection *Connection) optionals.Optional[chan generic.Pair[int, []byte]] {
+		return optionals.Some(connection.GetOutgoing())
+	})
 }
 
 func (ctx *RequestContext) GetRequest() proto.Request {
 	return ctx.request
 }
+
+func (ctx *RequestContext) GetMeta(key string) optionals.Optional[string] {
+	return ctx.request.GetMeta(key)
+}
+
+func (ctx *RequestContext) GetMetaAll() map[string]string {
+	return ctx.request.GetMetaAll()
+}
+
+func (ctx *RequestContext) Context() context.Context {
+	return ctx.ctx
+}
+
+func (ctx *RequestContext) Done() <-chan struct{} {
+	return ctx.ctx.Done()
+}
+
+func (ctx *RequestContext) Err() error {
+	return ctx.ctx.Err()
+}
+
+func (ctx *RequestContext) Cancel() {
+	ctx.cancel()
+}
+
+func (ctx *RequestContext) Match(connection *Connection, key string) bool {
+	if ctx.connection.IsEmpty() || ctx.connection.Get() != connection {
+		return false
+	}
+
+	return ctx.request.GetKey() == key
+}
+
+func (ctx *RequestContext) GetSession() optionals.Optional[*Session] {
+	return optionals.FlatMap(ctx.connection, func(connection *Connection) optionals.Optional[*Session] {
+		return optionals.Some(connection.GetSession())
+	})
+}
+
+func (ctx *RequestContext) GetPrincipal() string {
+	return optionals.FlatMap(ctx.connection, func(connection *Connection) optionals.Optional[string] {
+		return optionals.Some(connection.GetPrincipal())
+	}).GetDefault("")
+}
+
+func (ctx *RequestContext) SetPrincipal(principal string) (err error) {
+	var (
+		connection *Connection
+	)
+
+	connection, err = ctx.connection.GetTry()
+	if err != nil {
+		return ErrNoConnection
+	}
+
+	connection.SetPrincipal(principal)
+	return
+}
+
+func (ctx *RequestContext) Notify(topic string, data map[string]any) (err error) {
+	var (
+		connection *Connection
+	)
+
+	connection, err = ctx.connection.GetTry()
+	if err != nil {
+		return ErrNoConnection
+	}
+
+	return connection.Notify(topic, data)
+}
@@ -1,8 +1,10 @@
 package server
 
 import (
+	"errors"
 	"log"
 	"log/slog"
+	"slices"
 	"time"
 
 	"github.com/heartbytenet/go-lerpc/pkg/client"
@@ -16,39 +18,112 @@ import (
 var (
 	ErrorHandlerNotFound = "handler not found"
 	ErrorAuthFailed      = "handler auth failed"
+	ErrorQueueFull       = "executor queue is full"
+	ErrorInvalidParams   = "invalid params"
 )
 
 type Executor struct {
 	queue      *sync.Locked[[]generic.Pair[*RequestContext, *proto.Promise[proto.Result]]]
 	queueLimit int
+	pool       int
+	running    *sync.Locked[[]*RequestContext]
 	handlers   *sync.Locked[[]Handler]
+	streams    *sync.Locked[[]StreamHandler]
+	signer     *Signer
+	pubsub     *PubSub
+	registry   *ConnectionRegistry
 
 	downloadHandlers *sync.Locked[[]DownloadHandler]
+	uploadHandlers   *sync.Locked[[]UploadHandler]
 }
 
 func NewExecutor(queueLimit int) (executor *Executor) {
 	executor = &Executor{
 		queue:            sync.NewLocked(make([]generic.Pair[*RequestContext, *proto.Promise[proto.Result]], 0)),
 		queueLimit:       queueLimit,
+		pool:             1,
+		running:          sync.NewLocked(make([]*RequestContext, 0)),
 		handlers:         sync.NewLocked(make([]Handler, 0)),
+		streams:          sync.NewLocked(make([]StreamHandler, 0)),
+		signer:           NewSignerRandom(),
+		pubsub:           NewPubSub(NewSettingsDefault().EventsReplay),
+		registry:         NewConnectionRegistry(),
 		downloadHandlers: sync.NewLocked(make([]DownloadHandler, 0)),
+		uploadHandlers:   sync.NewLocked(make([]UploadHandler, 0)),
 	}
 
 	return executor
 }
 
+func NewExecutorWithSettings(settings Settings) (executor *Executor) {
+	executor = NewExecutor(settings.ExecutorLimit)
+	executor.SetSigner(settings.NewSigner())
+	executor.SetPubSub(NewPubSub(settings.EventsReplay))
+	executor.SetPool(settings.ExecutorPool)
+
+	return executor
+}
+
+func (executor *Executor) GetSigner() *Signer {
+	return executor.signer
+}
+
+func (executor *Executor) SetSigner(signer *Signer) {
+	executor.signer = signer
+}
+
+func (executor *Executor) GetPubSub() *PubSub {
+	return executor.pubsub
+}
+
+func (executor *Executor) SetPubSub(pubsub *PubSub) {
+	executor.pubsub = pubsub
+}
+
+func (executor *Executor) GetPool() int {
+	return executor.pool
+}
+
+func (executor *Executor) SetPool(pool int) {
+	executor.pool = max(pool, 1)
+}
+
+func (executor *Executor) GetRegistry() *ConnectionRegistry {
+	return executor.registry
+}
+
 func (executor *Executor) AddHandler(handler Handler) {
 	executor.handlers.Map(func(data []Handler) []Handler {
 		return append(data, handler)
 	})
 }
 
+func (executor *Executor) AddStreamHandler(handler StreamHandler) {
+	executor.streams.Map(func(data []StreamHandler) []StreamHandler {
+		return append(data, handler)
+	})
+}
+
 func (executor *Executor) AddDownloadHandler(handler DownloadHandler) {
 	executor.downloadHandlers.Map(func(data []DownloadHandler) []DownloadHandler {
 		return append(data, handler)
 	})
 }
 
+func (executor *Executor) AddUploadHandler(handler UploadHandler) {
+	executor.uploadHandlers.Map(func(data []UploadHandler) []UploadHandler {
+		return append(data, handler)
+	})
+}
+
+func (executor *Executor) HasStreamHandlers() (flag bool) {
+	executor.streams.Apply(func(data []StreamHandler) {
+		flag = len(data) > 0
+	})
+
+	return
+}
+
 func (executor *Executor) GetHandler(namespace string, method string) (result optionals.Optional[Handler]) {
 	result = optionals.None[Handler]()
 
@@ -66,6 +141,23 @@ func (executor *Executor) GetHandler(namespace string, method string) (result op
 	return
 }
 
+func (executor *Executor) GetStreamHandler(namespace string, method string) (result optionals.Optional[StreamHandler]) {
+	result = optionals.None[StreamHandler]()
+
+	executor.streams.Apply(func(data []StreamHandler) {
+		for _, handler := range data {
+			if !handler.Match(namespace, method) {
+				continue
+			}
+
+			result = optionals.Some(handler)
+			break
+		}
+	})
+
+	return
+}
+
 func (executor *Executor) GetDownloadHandlerAlive(key string) (result optionals.Optional[DownloadHandler]) {
 	result = optionals.None[DownloadHandler]()
 
@@ -87,6 +179,27 @@ func (executor *Executor) GetDownloadHandlerAlive(key string) (result optionals.
 	return
 }
 
+func (executor *Executor) GetUploadHandlerAlive(key string) (result optionals.Optional[UploadHandler]) {
+	result = optionals.None[UploadHandler]()
+
+	executor.uploadHandlers.Apply(func(data []UploadHandler) {
+		for _, handler := range data {
+			if !handler.Match(key) {
+				continue
+			}
+
+			if !handler.GetIsAlive() {
+				continue
+			}
+
+			result = optionals.Some(handler)
+			break
+		}
+	})
+
+	return
+}
+
 func (executor *Executor) Start(loop time.Duration) (err error) {
 	go executor.LoopExecute(loop)
 	go executor.LoopClearHandlers()
@@ -97,18 +210,33 @@ func (executor *Executor) Start(loop time.Duration) (err error) {
 func (executor *Executor) LoopExecute(duration time.Duration) {
 	var (
 		ticker *time.Ticker
+		slots  chan struct{}
+		entry  generic.Pair[*RequestContext, *proto.Promise[proto.Result]]
 		err    error
 	)
 
 	ticker = time.NewTicker(duration)
+	slots = make(chan struct{}, executor.pool)
 
 	for {
 		<-ticker.C
-		err = executor.ExecuteOne()
+
+		slots <- struct{}{}
+
+		entry, err = executor.Dequeue().GetTry()
 		if err != nil {
-			log.Println("failed at executing request:", err)
+			<-slots
 			continue
 		}
+
+		go func(entry generic.Pair[*RequestContext, *proto.Promise[proto.Result]]) {
+			defer func() { <-slots }()
+
+			err := executor.ExecuteEntry(entry)
+			if err != nil {
+				log.Println("failed at executing request:", err)
+			}
+		}(entry)
 	}
 }
 
@@ -134,6 +262,19 @@ func (executor *Executor) LoopClearHandlers() {
 				next = append(next, handler)
 			}
 
+			return
+		})
+		executor.uploadHandlers.Map(func(curr []UploadHandler) (next []UploadHandler) {
+			next = make([]UploadHandler, 0)
+
+			for _, handler := range curr {
+				if !handler.GetIsAlive() {
+					continue
+				}
+
+				next = append(next, handler)
+			}
+
 			return
 		})
 	}
@@ -141,23 +282,23 @@ func (executor *Executor) LoopClearHandlers() {
 
 func (executor *Executor) CreateQueueEntry(
 	clientMode client.ClientMode,
-	outgoing chan generic.Pair[int, []byte],
+	connection *Connection,
 	request proto.Request,
 ) generic.Pair[*RequestContext, *proto.Promise[proto.Result]] {
 	return generic.NewPair(
-		NewRequestContext(executor, clientMode, outgoing, request),
+		NewRequestContext(executor, clientMode, connection, request),
 		proto.NewPromise[proto.Result](),
 	)
 }
 
-func (executor *Executor) PushRequest(clientMode client.ClientMode, outgoing chan generic.Pair[int, []byte], request proto.Request) (entry *proto.Promise[proto.Result], flag bool) {
+func (executor *Executor) PushRequest(clientMode client.ClientMode, connection *Connection, request proto.Request) (entry *proto.Promise[proto.Result], flag bool) {
 	executor.queue.Map(func(data []generic.Pair[*RequestContext, *proto.Promise[proto.Result]]) []generic.Pair[*RequestContext, *proto.Promise[proto.Result]] {
 		if len(data) >= executor.queueLimit {
 			flag = false
 			return data
 		}
 
-		value := executor.CreateQueueEntry(clientMode, outgoing, request)
+		value := executor.CreateQueueEntry(clientMode, connection, request)
 
 		entry = value.B()
 		flag = true
@@ -167,8 +308,39 @@ func (executor *Executor) PushRequest(clientMode client.ClientMode, outgoing cha
 	return
 }
 
-func (executor *Executor) ExecuteOne() (err error) {
-	entry := optionals.None[generic.Pair[*RequestContext, *proto.Promise[proto.Result]]]()
+func (executor *Executor) CancelRequest(connection *Connection, key string) (flag bool) {
+	executor.queue.Map(func(data []generic.Pair[*RequestContext, *proto.Promise[proto.Result]]) []generic.Pair[*RequestContext, *proto.Promise[proto.Result]] {
+		return slices.DeleteFunc(data, func(entry generic.Pair[*RequestContext, *proto.Promise[proto.Result]]) bool {
+			if !entry.A().Match(connection, key) {
+				return false
+			}
+
+			entry.A().Cancel()
+			entry.B().Failed(proto.ErrCancelled)
+			flag = true
+			return true
+		})
+	})
+	if flag {
+		return
+	}
+
+	executor.running.Apply(func(data []*RequestContext) {
+		for _, ctx := range data {
+			if !ctx.Match(connection, key) {
+				continue
+			}
+
+			ctx.Cancel()
+			flag = true
+		}
+	})
+
+	return
+}
+
+func (executor *Executor) Dequeue() (entry optionals.Optional[generic.Pair[*RequestContext, *proto.Promise[proto.Result]]]) {
+	entry = optionals.None[generic.Pair[*RequestContext, *proto.Promise[proto.Result]]]()
 
 	executor.queue.Map(func(data []generic.Pair[*RequestContext, *proto.Promise[proto.Result]]) []generic.Pair[*RequestContext, *proto.Promise[proto.Result]] {
 		if len(data) < 1 {
@@ -176,40 +348,122 @@ func (executor *Executor) ExecuteOne() (err error) {
 		}
 
 		entry = optionals.Some(data[0])
+
+		executor.running.Map(func(running []*RequestContext) []*RequestContext {
+			return append(running, data[0].A())
+		})
+
 		return data[1:]
 	})
 
-	entry.IfPresent(func(value generic.Pair[*RequestContext, *proto.Promise[proto.Result]]) {
-		var (
-			ctx     *RequestContext
-			promise *proto.Promise[proto.Result]
-			result  proto.Result
-		)
+	return
+}
 
-		ctx, promise = value.A(), value.B()
+func (executor *Executor) ExecuteOne() (err error) {
+	executor.Dequeue().IfPresent(func(value generic.Pair[*RequestContext, *proto.Promise[proto.Result]]) {
+		err = executor.ExecuteEntry(value)
+	})
 
-		result, err = executor.ExecuteRequest(ctx)
-		if err != nil {
-			return
-		}
+	return
+}
+
+func (executor *Executor) ExecuteEntry(value generic.Pair[*RequestContext, *proto.Promise[proto.Result]]) (err error) {
+	var (
+		ctx     *RequestContext
+		promise *proto.Promise[proto.Result]
+		result  proto.Result
+	)
+
+	ctx, promise = value.A(), value.B()
+
+	defer executor.running.Map(func(data []*RequestContext) []*RequestContext {
+		return slices.DeleteFunc(data, func(entry *RequestContext) bool {
+			return entry == ctx
+		})
+	})
+	defer ctx.Cancel()
+
+	if ctx.Err() != nil {
+		promise.Failed(proto.ErrCancelled)
+		return
+	}
+
+	result, err = executor.ExecuteRequest(ctx)
+	if err != nil {
+		return
+	}
+
+	if ctx.Err() != nil {
+		promise.Failed(proto.ErrCancelled)
+		return
+	}
+
+	promise.Complete(result)
+	return
+}
+
+func (executor *Executor) ExecuteStream(ctx *RequestContext, stream *Stream) (err error) {
+	var (
+		handler StreamHandler
+	)
+
+	defer ctx.Cancel()
 
-		promise.Complete(result)
+	request := ctx.GetRequest()
+
+	err = proto.CheckVersion(request.GetVersion())
+	if err != nil {
+		return
+	}
+
+	handler, err = executor.GetStreamHandler(request.GetNamespace(), request.GetMethod()).GetTry()
+	if err != nil {
+		return errors.New(ErrorHandlerNotFound)
+	}
+
+	if !handler.Auth(ctx, request.Token) {
+		return errors.New(ErrorAuthFailed)
+	}
+
+	executor.running.Map(func(data []*RequestContext) []*RequestContext {
+		return append(data, ctx)
+	})
+	defer executor.running.Map(func(data []*RequestContext) []*RequestContext {
+		return slices.DeleteFunc(data, func(entry *RequestContext) bool {
+			return entry == ctx
+		})
 	})
+
+	err = handler.Stream(ctx, stream)
 	if err != nil {
 		return
 	}
 
+	if ctx.Err() != nil {
+		return proto.ErrCancelled
+	}
+
 	return
 }
 
 func (executor *Executor) ExecuteRequest(ctx *RequestContext) (result proto.Result, err error) {
 	request := ctx.GetRequest()
 
+	err = proto.CheckVersion(request.GetVersion())
+	if err != nil {
+		result = proto.NewResult().
+			WithKey(request.GetKey()).
+			WithCode(proto.ResultCodeError).
+			WithMessage(err.Error())
+		return result, nil
+	}
+
 	executor.GetHandler(request.GetNamespace(), request.GetMethod()).
 		IfPresentElse(
 			func(handler Handler) {
 				if !handler.Auth(ctx, request.Token) {
 					result = proto.NewResult().
+						WithKey(request.GetKey()).
 						WithCode(proto.ResultCodeError).
 						WithMessage(ErrorAuthFailed)
 					return
@@ -219,6 +473,7 @@ func (executor *Executor) ExecuteRequest(ctx *RequestContext) (result proto.Resu
 			},
 			func() {
 				result = proto.NewResult().
+					WithKey(request.GetKey()).
 					WithCode(proto.ResultCodeError).
 					WithMessage(ErrorHandlerNotFound)
 			},
@@ -0,0 +1,79 @@
+package server
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/heartbytenet/go-lerpc/pkg/client"
+	"github.com/heartbytenet/go-lerpc/pkg/proto"
+)
+
+func TestExecutorPool(t *testing.T) {
+	executor := NewExecutor(16)
+	executor.SetPool(2)
+
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+
+	executor.AddHandler(NewHandlerWith("test", "wait", nil, func(ctx *RequestContext, request proto.Request) proto.Result {
+		started <- struct{}{}
+		<-release
+		return proto.NewResult().WithCode(proto.ResultCodeSuccess)
+	}))
+
+	if err := executor.Start(time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+
+	promises := make([]*proto.Promise[proto.Result], 0, 2)
+	for i := 0; i < 2; i++ {
+		promise, flag := executor.PushRequest(client.ClientModeHttp, nil, proto.NewRequest().
+			WithNamespace("test").
+			WithMethod("wait"))
+		if !flag {
+			t.Fatal("queue rejected request")
+		}
+
+		promises = append(promises, promise)
+	}
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-started:
+		case <-time.After(time.Second):
+			t.Fatalf("only %d of 2 requests ran concurrently", i)
+		}
+	}
+
+	close(release)
+
+	for _, promise := range promises {
+		if _, err := promise.Await(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestHandleExecuteLimit(t *testing.T) {
+	settings := NewSettingsDefault()
+	settings.MaxMessageSize = 64
+
+	server := NewServerWithSettings(settings)
+	server.Routes()
+
+	test := httptest.NewServer(server.GetEngine())
+	t.Cleanup(test.Close)
+
+	res, err := http.Post(test.URL+"/execute", "application/json", bytes.NewReader(bytes.Repeat([]byte(" "), 128)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = res.Body.Close()
+
+	if res.StatusCode != 413 {
+		t.Fatalf("expected body over the message limit to fail, got %d", res.StatusCode)
+	}
+}
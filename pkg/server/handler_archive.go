@@ -0,0 +1,243 @@
+package server
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type ArchiveFormat int
+
+const (
+	ArchiveFormatZip ArchiveFormat = iota
+	ArchiveFormatTarGz
+)
+
+var (
+	ErrArchiveFormat = errors.New("invalid archive format")
+)
+
+func (format ArchiveFormat) ContentType() (string, error) {
+	switch format {
+	case ArchiveFormatZip:
+		return "application/zip", nil
+
+	case ArchiveFormatTarGz:
+		return "application/gzip", nil
+
+	default:
+		return "", fmt.Errorf("%w: %d", ErrArchiveFormat, format)
+	}
+}
+
+type ArchiveEntry struct {
+	Name string
+	Path string
+}
+
+func NewDownloadHandlerArchive(
+	key string,
+	lifetime time.Duration,
+	limit int,
+	format ArchiveFormat,
+	paths ...string,
+) (*DownloadHandlerFunc, error) {
+	entries := make([]ArchiveEntry, 0, len(paths))
+	for _, path := range paths {
+		entries = append(entries, ArchiveEntry{
+			Name: filepath.Base(path),
+			Path: path,
+		})
+	}
+
+	return NewDownloadHandlerArchiveEntries(key, lifetime, limit, format, func() ([]ArchiveEntry, error) {
+		return entries, nil
+	})
+}
+
+func NewDownloadHandlerArchiveDir(
+	key string,
+	lifetime time.Duration,
+	limit int,
+	format ArchiveFormat,
+	dir string,
+) (*DownloadHandlerFunc, error) {
+	return NewDownloadHandlerArchiveEntries(key, lifetime, limit, format, func() (entries []ArchiveEntry, err error) {
+		err = filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if !entry.Type().IsRegular() {
+				return nil
+			}
+
+			name, err := filepath.Rel(dir, path)
+			if err != nil {
+				return err
+			}
+
+			entries = append(entries, ArchiveEntry{
+				Name: filepath.ToSlash(name),
+				Path: path,
+			})
+
+			return nil
+		})
+
+		return
+	})
+}
+
+func NewDownloadHandlerArchiveEntries(
+	key string,
+	lifetime time.Duration,
+	limit int,
+	format ArchiveFormat,
+	fnEntries func() ([]ArchiveEntry, error),
+) (handler *DownloadHandlerFunc, err error) {
+	var (
+		contentType string
+	)
+
+	contentType, err = format.ContentType()
+	if err != nil {
+		return
+	}
+
+	handler = NewDownloadHandlerFunc(key, lifetime, limit, contentType, func(writer io.Writer) (err error) {
+		var (
+			entries []ArchiveEntry
+		)
+
+		entries, err = fnEntries()
+		if err != nil {
+			return
+		}
+
+		switch format {
+		case ArchiveFormatZip:
+			return WriteArchiveZip(writer, entries)
+
+		case ArchiveFormatTarGz:
+			return WriteArchiveTarGz(writer, entries)
+
+		default:
+			return fmt.Errorf("%w: %d", ErrArchiveFormat, format)
+		}
+	})
+
+	return
+}
+
+func WriteArchiveZip(writer io.Writer, entries []ArchiveEntry) (err error) {
+	var (
+		archive *zip.Writer
+	)
+
+	archive = zip.NewWriter(writer)
+
+	for _, entry := range entries {
+		err = writeArchiveEntry(entry, func(info os.FileInfo, file *os.File) (err error) {
+			var (
+				header *zip.FileHeader
+				target io.Writer
+			)
+
+			header, err = zip.FileInfoHeader(info)
+			if err != nil {
+				return
+			}
+
+			header.Name = entry.Name
+			header.Method = zip.Deflate
+
+			target, err = archive.CreateHeader(header)
+			if err != nil {
+				return
+			}
+
+			_, err = io.Copy(target, file)
+			return
+		})
+		if err != nil {
+			return
+		}
+	}
+
+	return archive.Close()
+}
+
+func WriteArchiveTarGz(writer io.Writer, entries []ArchiveEntry) (err error) {
+	var (
+		compressor *gzip.Writer
+		archive    *tar.Writer
+	)
+
+	compressor = gzip.NewWriter(writer)
+	archive = tar.NewWriter(compressor)
+
+	for _, entry := range entries {
+		err = writeArchiveEntry(entry, func(info os.FileInfo, file *os.File) (err error) {
+			var (
+				header *tar.Header
+			)
+
+			header, err = tar.FileInfoHeader(info, "")
+			if err != nil {
+				return
+			}
+
+			header.Name = entry.Name
+
+			err = archive.WriteHeader(header)
+			if err != nil {
+				return
+			}
+
+			_, err = io.Copy(archive, file)
+			return
+		})
+		if err != nil {
+			return
+		}
+	}
+
+	err = archive.Close()
+	if err != nil {
+		return
+	}
+
+	return compressor.Close()
+}
+
+func writeArchiveEntry(entry ArchiveEntry, fn func(info os.FileInfo, file *os.File) error) (err error) {
+	var (
+		file *os.File
+		info os.FileInfo
+	)
+
+	file, err = os.Open(entry.Path)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	info, err = file.Stat()
+	if err != nil {
+		return
+	}
+
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("not a regular file: %s", entry.Path)
+	}
+
+	return fn(info, file)
+}
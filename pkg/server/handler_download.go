@@ -1,11 +1,17 @@
 package server
 
 import (
+	"bytes"
+	"errors"
 	"io"
 	"os"
 	"time"
 )
 
+var (
+	ErrDownloadRemoved = errors.New("download has been removed")
+)
+
 type DownloadHandler interface {
 	Match(key string) bool
 	Lifetime() time.Duration
@@ -16,25 +22,24 @@ type DownloadHandler interface {
 	Remove() error
 }
 
-type DownloadHandlerFile struct {
+type DownloadHandlerBase struct {
 	key      string
 	lifetime time.Duration
 	limit    int
 	count    int
 	isAlive  bool
+	removed  bool
 
 	contentType string
-	path        string
 }
 
-func NewDownloadHandlerFile(
+func NewDownloadHandlerBase(
 	key string,
 	lifetime time.Duration,
 	limit int,
 	contentType string,
-	path string,
-) *DownloadHandlerFile {
-	return &DownloadHandlerFile{
+) DownloadHandlerBase {
+	return DownloadHandlerBase{
 		key:      key,
 		lifetime: lifetime,
 		limit:    limit,
@@ -42,23 +47,22 @@ func NewDownloadHandlerFile(
 		isAlive:  true,
 
 		contentType: contentType,
-		path:        path,
 	}
 }
 
-func (handler *DownloadHandlerFile) Match(key string) bool {
+func (handler *DownloadHandlerBase) Match(key string) bool {
 	return handler.key == key
 }
 
-func (handler *DownloadHandlerFile) Lifetime() time.Duration {
+func (handler *DownloadHandlerBase) Lifetime() time.Duration {
 	return handler.lifetime
 }
 
-func (handler *DownloadHandlerFile) Limit() int {
+func (handler *DownloadHandlerBase) Limit() int {
 	return handler.limit
 }
 
-func (handler *DownloadHandlerFile) GetIsAlive() bool {
+func (handler *DownloadHandlerBase) GetIsAlive() bool {
 	if handler.count == handler.Limit() {
 		handler.isAlive = false
 	}
@@ -66,10 +70,30 @@ func (handler *DownloadHandlerFile) GetIsAlive() bool {
 	return handler.isAlive
 }
 
-func (handler *DownloadHandlerFile) GetContentType() string {
+func (handler *DownloadHandlerBase) GetContentType() string {
 	return handler.contentType
 }
 
+type DownloadHandlerFile struct {
+	DownloadHandlerBase
+
+	path string
+}
+
+func NewDownloadHandlerFile(
+	key string,
+	lifetime time.Duration,
+	limit int,
+	contentType string,
+	path string,
+) *DownloadHandlerFile {
+	return &DownloadHandlerFile{
+		DownloadHandlerBase: NewDownloadHandlerBase(key, lifetime, limit, contentType),
+
+		path: path,
+	}
+}
+
 func (handler *DownloadHandlerFile) Pull() (reader io.Reader, err error) {
 	var (
 		file *os.File
@@ -94,3 +118,93 @@ func (handler *DownloadHandlerFile) Remove() (err error) {
 
 	return
 }
+
+type DownloadHandlerBytes struct {
+	DownloadHandlerBase
+
+	data []byte
+}
+
+func NewDownloadHandlerBytes(
+	key string,
+	lifetime time.Duration,
+	limit int,
+	contentType string,
+	data []byte,
+) *DownloadHandlerBytes {
+	return &DownloadHandlerBytes{
+		DownloadHandlerBase: NewDownloadHandlerBase(key, lifetime, limit, contentType),
+
+		data: data,
+	}
+}
+
+func (handler *DownloadHandlerBytes) Pull() (reader io.Reader, err error) {
+	if handler.removed {
+		return nil, ErrDownloadRemoved
+	}
+
+	handler.count++
+
+	reader = bytes.NewReader(handler.data)
+	return
+}
+
+func (handler *DownloadHandlerBytes) Remove() (err error) {
+	handler.data = nil
+	handler.removed = true
+	handler.isAlive = false
+
+	return
+}
+
+type DownloadHandlerFunction func(writer io.Writer) error
+
+type DownloadHandlerFunc struct {
+	DownloadHandlerBase
+
+	fnWrite DownloadHandlerFunction
+}
+
+func NewDownloadHandlerFunc(
+	key string,
+	lifetime time.Duration,
+	limit int,
+	contentType string,
+	fnWrite DownloadHandlerFunction,
+) *DownloadHandlerFunc {
+	return &DownloadHandlerFunc{
+		DownloadHandlerBase: NewDownloadHandlerBase(key, lifetime, limit, contentType),
+
+		fnWrite: fnWrite,
+	}
+}
+
+func (handler *DownloadHandlerFunc) Pull() (reader io.Reader, err error) {
+	var (
+		pipeReader *io.PipeReader
+		pipeWriter *io.PipeWriter
+	)
+
+	if handler.removed {
+		return nil, ErrDownloadRemoved
+	}
+
+	handler.count++
+
+	pipeReader, pipeWriter = io.Pipe()
+
+	go func() {
+		pipeWriter.CloseWithError(handler.fnWrite(pipeWriter))
+	}()
+
+	reader = pipeReader
+	return
+}
+
+func (handler *DownloadHandlerFunc) Remove() (err error) {
+	handler.removed = true
+	handler.isAlive = false
+
+	return
+}
@@ -0,0 +1,172 @@
+package server
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestDownloadHandlerBytesPull(t *testing.T) {
+	handler := NewDownloadHandlerBytes("k", time.Minute, 2, "text/plain", []byte("hello"))
+
+	if !handler.Match("k") || handler.Match("other") {
+		t.Fatal("unexpected key match")
+	}
+
+	if handler.GetContentType() != "text/plain" {
+		t.Fatalf("unexpected content type %q", handler.GetContentType())
+	}
+
+	for i := 0; i < 2; i++ {
+		if !handler.GetIsAlive() {
+			t.Fatalf("handler died after %d pulls", i)
+		}
+
+		reader, err := handler.Pull()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(data) != "hello" {
+			t.Fatalf("unexpected data %q", data)
+		}
+	}
+
+	if handler.GetIsAlive() {
+		t.Fatal("handler still alive after reaching its limit")
+	}
+}
+
+func TestDownloadHandlerBytesRemove(t *testing.T) {
+	handler := NewDownloadHandlerBytes("k", time.Minute, 1, "text/plain", []byte("hello"))
+
+	if err := handler.Remove(); err != nil {
+		t.Fatal(err)
+	}
+
+	if handler.GetIsAlive() {
+		t.Fatal("removed handler is still alive")
+	}
+
+	_, err := handler.Pull()
+	if !errors.Is(err, ErrDownloadRemoved) {
+		t.Fatalf("expected removed download, got %v", err)
+	}
+}
+
+func TestDownloadHandlerFuncRemove(t *testing.T) {
+	handler := NewDownloadHandlerFunc("k", time.Minute, 1, "text/plain", func(writer io.Writer) error {
+		return nil
+	})
+
+	if err := handler.Remove(); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := handler.Pull()
+	if !errors.Is(err, ErrDownloadRemoved) {
+		t.Fatalf("expected removed download, got %v", err)
+	}
+}
+
+func TestDownloadHandlerFuncPull(t *testing.T) {
+	handler := NewDownloadHandlerFunc("k", time.Minute, 1, "text/csv", func(writer io.Writer) error {
+		_, err := io.WriteString(writer, "a,b\n1,2\n")
+		return err
+	})
+
+	if handler.Limit() != 1 || handler.Lifetime() != time.Minute {
+		t.Fatal("unexpected limit or lifetime")
+	}
+
+	reader, err := handler.Pull()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != "a,b\n1,2\n" {
+		t.Fatalf("unexpected data %q", data)
+	}
+
+	if handler.GetIsAlive() {
+		t.Fatal("handler still alive after reaching its limit")
+	}
+}
+
+func TestDownloadHandlerFuncError(t *testing.T) {
+	failure := errors.New("generator failed")
+
+	handler := NewDownloadHandlerFunc("k", time.Minute, 1, "text/plain", func(writer io.Writer) error {
+		_, _ = io.WriteString(writer, "partial")
+		return failure
+	})
+
+	reader, err := handler.Pull()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := io.ReadAll(reader)
+	if !errors.Is(err, failure) {
+		t.Fatalf("expected generator error, got %v", err)
+	}
+
+	if string(data) != "partial" {
+		t.Fatalf("unexpected data %q", data)
+	}
+}
+
+func TestDownloadHandlerFuncEarlyClose(t *testing.T) {
+	done := make(chan error, 1)
+
+	handler := NewDownloadHandlerFunc("k", time.Minute, 1, "text/plain", func(writer io.Writer) (err error) {
+		defer func() { done <- err }()
+
+		for {
+			_, err = writer.Write(make([]byte, 1024))
+			if err != nil {
+				return
+			}
+		}
+	})
+
+	reader, err := handler.Pull()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = reader.Read(make([]byte, 16))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	closer, flag := reader.(io.Closer)
+	if !flag {
+		t.Fatal("reader is not closable")
+	}
+
+	if err = closer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err = <-done:
+		if !errors.Is(err, io.ErrClosedPipe) {
+			t.Fatalf("expected closed pipe, got %v", err)
+		}
+
+	case <-time.After(time.Second):
+		t.Fatal("generator did not stop after the reader was closed")
+	}
+}
@@ -0,0 +1,64 @@
+package server
+
+type StreamHandler interface {
+	Match(namespace string, method string) bool
+	Auth(ctx *RequestContext, token string) bool
+	Stream(ctx *RequestContext, stream *Stream) error
+}
+
+type StreamHandlerBase struct {
+	fnMatch  HandlerMatchFunction
+	fnAuth   HandlerAuthFunction
+	fnStream StreamHandlerFunction
+}
+
+type StreamHandlerFunction func(ctx *RequestContext, stream *Stream) error
+
+func NewStreamHandler(
+	fnMatch HandlerMatchFunction,
+	fnAuth HandlerAuthFunction,
+	fnStream StreamHandlerFunction,
+) StreamHandler {
+	return &StreamHandlerBase{
+		fnMatch,
+		fnAuth,
+		fnStream,
+	}
+}
+
+func NewStreamHandlerWith(namespace string, method string, fnAuth HandlerAuthFunction, fnStream StreamHandlerFunction) StreamHandler {
+	return NewStreamHandler(
+		func(n string, m string) bool {
+			if n != namespace {
+				return false
+			}
+
+			if m != method {
+				return false
+			}
+
+			return true
+		},
+		fnAuth,
+		fnStream)
+}
+
+func (handler *StreamHandlerBase) Match(namespace string, method string) bool {
+	if handler.fnMatch == nil {
+		return false
+	}
+
+	return handler.fnMatch(namespace, method)
+}
+
+func (handler *StreamHandlerBase) Auth(ctx *RequestContext, token string) bool {
+	if handler.fnAuth == nil {
+		return true
+	}
+
+	return handler.fnAuth(ctx, token)
+}
+
+func (handler *StreamHandlerBase) Stream(ctx *RequestContext, stream *Stream) error {
+	return handler.fnStream(ctx, stream)
+}
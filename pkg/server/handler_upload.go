@@ -0,0 +1,359 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding"
+	"errors"
+	"hash"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/goccy/go-json"
+)
+
+var (
+	ErrUploadOffset   = errors.New("upload offset mismatch")
+	ErrUploadLength   = errors.New("upload exceeds declared length")
+	ErrUploadChecksum = errors.New("upload checksum mismatch")
+	ErrUploadComplete = errors.New("upload already complete")
+)
+
+type UploadHandler interface {
+	Match(key string) bool
+	Lifetime() time.Duration
+	GetIsAlive() bool
+	GetOffset() (int64, error)
+	GetLength() int64
+	Write(offset int64, data []byte, chunkSum []byte, fileSum []byte) (int64, error)
+	Remove() error
+}
+
+type UploadCompleteFunction func(handler *UploadHandlerFile)
+
+type UploadHandlerFile struct {
+	key      string
+	lifetime time.Duration
+	created  time.Time
+	length   int64
+	checksum []byte
+
+	path       string
+	fnComplete UploadCompleteFunction
+
+	mutex  sync.Mutex
+	loaded bool
+	done   bool
+	offset int64
+	hash   hash.Hash
+}
+
+type uploadState struct {
+	Offset   int64  `json:"offset"`
+	Length   int64  `json:"length"`
+	Checksum []byte `json:"checksum,omitempty"`
+	Hash     []byte `json:"hash"`
+}
+
+func NewUploadHandlerFile(
+	key string,
+	lifetime time.Duration,
+	length int64,
+	checksum []byte,
+	path string,
+	fnComplete UploadCompleteFunction,
+) *UploadHandlerFile {
+	return &UploadHandlerFile{
+		key:      key,
+		lifetime: lifetime,
+		created:  time.Now(),
+		length:   length,
+		checksum: checksum,
+
+		path:       path,
+		fnComplete: fnComplete,
+	}
+}
+
+func (handler *UploadHandlerFile) Match(key string) bool {
+	return handler.key == key
+}
+
+func (handler *UploadHandlerFile) Lifetime() time.Duration {
+	return handler.lifetime
+}
+
+func (handler *UploadHandlerFile) GetIsAlive() bool {
+	return time.Since(handler.created) < handler.Lifetime()
+}
+
+func (handler *UploadHandlerFile) GetLength() int64 {
+	return handler.length
+}
+
+func (handler *UploadHandlerFile) GetPath() string {
+	return handler.path
+}
+
+func (handler *UploadHandlerFile) GetPathPart() string {
+	return handler.path + ".part"
+}
+
+func (handler *UploadHandlerFile) GetPathState() string {
+	return handler.path + ".part.state"
+}
+
+func (handler *UploadHandlerFile) GetOffset() (offset int64, err error) {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	err = handler.load()
+	if err != nil {
+		return
+	}
+
+	offset = handler.offset
+	return
+}
+
+func (handler *UploadHandlerFile) Write(offset int64, data []byte, chunkSum []byte, fileSum []byte) (next int64, err error) {
+	var (
+		file *os.File
+		sum  [sha256.Size]byte
+	)
+
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	err = handler.load()
+	if err != nil {
+		return
+	}
+
+	next = handler.offset
+
+	if handler.done {
+		err = ErrUploadComplete
+		return
+	}
+
+	if offset != handler.offset {
+		err = ErrUploadOffset
+		return
+	}
+
+	if handler.offset+int64(len(data)) > handler.length {
+		err = ErrUploadLength
+		return
+	}
+
+	if chunkSum != nil {
+		sum = sha256.Sum256(data)
+		if !bytes.Equal(sum[:], chunkSum) {
+			err = ErrUploadChecksum
+			return
+		}
+	}
+
+	if fileSum != nil {
+		if handler.checksum != nil && !bytes.Equal(handler.checksum, fileSum) {
+			err = ErrUploadChecksum
+			return
+		}
+
+		handler.checksum = fileSum
+	}
+
+	file, err = os.OpenFile(handler.GetPathPart(), os.O_WRONLY|os.O_CREATE, 0o644)
+	if err != nil {
+		return
+	}
+
+	_, err = file.WriteAt(data, offset)
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return
+	}
+
+	handler.hash.Write(data)
+	handler.offset += int64(len(data))
+	next = handler.offset
+
+	if handler.offset < handler.length {
+		err = handler.save()
+		return
+	}
+
+	err = handler.finish()
+	if err != nil {
+		next = handler.offset
+		return
+	}
+
+	return
+}
+
+func (handler *UploadHandlerFile) Remove() (err error) {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	return handler.reset()
+}
+
+func (handler *UploadHandlerFile) load() (err error) {
+	var (
+		data  []byte
+		state uploadState
+		info  os.FileInfo
+	)
+
+	if handler.loaded {
+		return
+	}
+
+	handler.hash = sha256.New()
+	handler.offset = 0
+
+	data, err = os.ReadFile(handler.GetPathState())
+	if errors.Is(err, os.ErrNotExist) {
+		info, err = os.Stat(handler.GetPath())
+		if err == nil && info.Size() == handler.length {
+			handler.offset = handler.length
+			handler.done = true
+		}
+
+		err = nil
+		handler.loaded = true
+		return
+	}
+	if err != nil {
+		return
+	}
+
+	err = json.Unmarshal(data, &state)
+	if err != nil {
+		return
+	}
+
+	if state.Length != handler.length {
+		err = handler.reset()
+		if err != nil {
+			return
+		}
+
+		handler.loaded = true
+		return
+	}
+
+	info, err = os.Stat(handler.GetPathPart())
+	if err != nil {
+		return
+	}
+
+	if info.Size() < state.Offset {
+		err = handler.reset()
+		if err != nil {
+			return
+		}
+
+		handler.loaded = true
+		return
+	}
+
+	err = os.Truncate(handler.GetPathPart(), state.Offset)
+	if err != nil {
+		return
+	}
+
+	err = handler.hash.(encoding.BinaryUnmarshaler).UnmarshalBinary(state.Hash)
+	if err != nil {
+		return
+	}
+
+	if handler.checksum == nil {
+		handler.checksum = state.Checksum
+	}
+
+	handler.offset = state.Offset
+	handler.loaded = true
+	return
+}
+
+func (handler *UploadHandlerFile) save() (err error) {
+	var (
+		state uploadState
+		data  []byte
+	)
+
+	state = uploadState{
+		Offset:   handler.offset,
+		Length:   handler.length,
+		Checksum: handler.checksum,
+	}
+
+	state.Hash, err = handler.hash.(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		return
+	}
+
+	data, err = json.Marshal(state)
+	if err != nil {
+		return
+	}
+
+	err = os.WriteFile(handler.GetPathState()+".tmp", data, 0o644)
+	if err != nil {
+		return
+	}
+
+	return os.Rename(handler.GetPathState()+".tmp", handler.GetPathState())
+}
+
+func (handler *UploadHandlerFile) finish() (err error) {
+	if handler.checksum != nil && !bytes.Equal(handler.hash.Sum(nil), handler.checksum) {
+		err = handler.reset()
+		if err != nil {
+			return
+		}
+
+		return ErrUploadChecksum
+	}
+
+	err = os.Rename(handler.GetPathPart(), handler.GetPath())
+	if err != nil {
+		return
+	}
+
+	err = os.Remove(handler.GetPathState())
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return
+	}
+
+	handler.done = true
+
+	if handler.fnComplete != nil {
+		handler.fnComplete(handler)
+	}
+
+	return nil
+}
+
+func (handler *UploadHandlerFile) reset() (err error) {
+	handler.hash = sha256.New()
+	handler.offset = 0
+
+	for _, path := range []string{handler.GetPathPart(), handler.GetPathState()} {
+		err = os.Remove(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,275 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/heartbytenet/go-lerpc/pkg/proto"
+)
+
+func testUploadSum(data []byte) []byte {
+	sum := sha256.Sum256(data)
+	return sum[:]
+}
+
+func testUploadHandler(t *testing.T, data []byte, checksum []byte, fnComplete UploadCompleteFunction) *UploadHandlerFile {
+	return NewUploadHandlerFile("k", time.Minute, int64(len(data)), checksum,
+		filepath.Join(t.TempDir(), "upload.bin"), fnComplete)
+}
+
+func TestUploadHandlerWrite(t *testing.T) {
+	var (
+		completed bool
+	)
+
+	data := []byte("hello resumable world")
+	handler := testUploadHandler(t, data, nil, func(*UploadHandlerFile) {
+		completed = true
+	})
+
+	offset, err := handler.Write(0, data[:5], testUploadSum(data[:5]), testUploadSum(data))
+	if err != nil || offset != 5 {
+		t.Fatalf("first chunk: offset %d, err %v", offset, err)
+	}
+
+	offset, err = handler.GetOffset()
+	if err != nil || offset != 5 {
+		t.Fatalf("offset after first chunk: %d, %v", offset, err)
+	}
+
+	offset, err = handler.Write(5, data[5:], testUploadSum(data[5:]), testUploadSum(data))
+	if err != nil || offset != int64(len(data)) {
+		t.Fatalf("last chunk: offset %d, err %v", offset, err)
+	}
+
+	if !completed {
+		t.Fatal("completion callback was not called")
+	}
+
+	result, err := os.ReadFile(handler.GetPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(result, data) {
+		t.Fatalf("unexpected file content %q", result)
+	}
+
+	if _, err = os.Stat(handler.GetPathState()); !errors.Is(err, os.ErrNotExist) {
+		t.Fatal("state file left behind after completion")
+	}
+
+	_, err = handler.Write(offset, []byte("x"), nil, nil)
+	if !errors.Is(err, ErrUploadComplete) {
+		t.Fatalf("expected upload complete, got %v", err)
+	}
+}
+
+func TestUploadHandlerOffsetConflict(t *testing.T) {
+	handler := testUploadHandler(t, []byte("0123456789"), nil, nil)
+
+	offset, err := handler.Write(3, []byte("345"), nil, nil)
+	if !errors.Is(err, ErrUploadOffset) {
+		t.Fatalf("expected offset mismatch, got %v", err)
+	}
+
+	if offset != 0 {
+		t.Fatalf("conflict reported offset %d", offset)
+	}
+}
+
+func TestUploadHandlerLength(t *testing.T) {
+	handler := testUploadHandler(t, []byte("0123"), nil, nil)
+
+	_, err := handler.Write(0, []byte("012345"), nil, nil)
+	if !errors.Is(err, ErrUploadLength) {
+		t.Fatalf("expected length error, got %v", err)
+	}
+}
+
+func TestUploadHandlerChunkChecksum(t *testing.T) {
+	handler := testUploadHandler(t, []byte("0123456789"), nil, nil)
+
+	_, err := handler.Write(0, []byte("01234"), testUploadSum([]byte("other")), nil)
+	if !errors.Is(err, ErrUploadChecksum) {
+		t.Fatalf("expected checksum error, got %v", err)
+	}
+
+	offset, err := handler.GetOffset()
+	if err != nil || offset != 0 {
+		t.Fatalf("rejected chunk moved offset to %d: %v", offset, err)
+	}
+}
+
+func TestUploadHandlerFileChecksum(t *testing.T) {
+	data := []byte("0123456789")
+	handler := testUploadHandler(t, data, testUploadSum([]byte("different")), nil)
+
+	_, err := handler.Write(0, data, nil, nil)
+	if !errors.Is(err, ErrUploadChecksum) {
+		t.Fatalf("expected checksum error, got %v", err)
+	}
+
+	offset, err := handler.GetOffset()
+	if err != nil || offset != 0 {
+		t.Fatalf("failed upload was not reset, offset %d: %v", offset, err)
+	}
+
+	if _, err = os.Stat(handler.GetPath()); !errors.Is(err, os.ErrNotExist) {
+		t.Fatal("file with a bad checksum was published")
+	}
+}
+
+func TestUploadHandlerRestore(t *testing.T) {
+	data := []byte("persisted upload state")
+	path := filepath.Join(t.TempDir(), "upload.bin")
+
+	first := NewUploadHandlerFile("k", time.Minute, int64(len(data)), testUploadSum(data), path, nil)
+
+	_, err := first.Write(0, data[:9], nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = os.Stat(first.GetPathState()); err != nil {
+		t.Fatalf("state file missing: %v", err)
+	}
+
+	second := NewUploadHandlerFile("k", time.Minute, int64(len(data)), nil, path, nil)
+
+	offset, err := second.GetOffset()
+	if err != nil || offset != 9 {
+		t.Fatalf("restored offset %d: %v", offset, err)
+	}
+
+	offset, err = second.Write(9, data[9:], nil, nil)
+	if err != nil || offset != int64(len(data)) {
+		t.Fatalf("resumed write: offset %d, err %v", offset, err)
+	}
+
+	result, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(result, data) {
+		t.Fatalf("unexpected file content %q", result)
+	}
+}
+
+func testUploadServer(t *testing.T, handler UploadHandler, limit int64) *httptest.Server {
+	settings := NewSettingsDefault()
+	settings.UploadLimit = limit
+
+	server := NewServerWithSettings(settings)
+	server.AddUploadHandler(handler)
+	server.Routes()
+
+	test := httptest.NewServer(server.GetEngine())
+	t.Cleanup(test.Close)
+
+	return test
+}
+
+func testUploadPatch(t *testing.T, url string, offset int64, data []byte) *http.Response {
+	req, err := http.NewRequest("PATCH", url, bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req.Header.Set("Content-Type", proto.ContentTypeUpload)
+	req.Header.Set(proto.HeaderUploadOffset, strconv.FormatInt(offset, 10))
+	req.Header.Set(proto.HeaderUploadChecksum, proto.FormatUploadChecksum(testUploadSum(data)))
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = res.Body.Close()
+
+	return res
+}
+
+func TestHandleUpload(t *testing.T) {
+	data := []byte("0123456789")
+	test := testUploadServer(t, testUploadHandler(t, data, nil, nil), 1024)
+
+	res, err := http.Head(test.URL + "/upload?key=k")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.StatusCode != 200 || res.Header.Get(proto.HeaderUploadOffset) != "0" || res.Header.Get(proto.HeaderUploadLength) != "10" {
+		t.Fatalf("unexpected head response %d %v", res.StatusCode, res.Header)
+	}
+
+	res = testUploadPatch(t, test.URL+"/upload?key=k", 0, data[:4])
+	if res.StatusCode != 204 || res.Header.Get(proto.HeaderUploadOffset) != "4" {
+		t.Fatalf("unexpected patch response %d %v", res.StatusCode, res.Header)
+	}
+
+	res, err = http.Head(test.URL + "/upload?key=k")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Header.Get(proto.HeaderUploadOffset) != "4" {
+		t.Fatalf("head did not report resumed offset: %v", res.Header)
+	}
+
+	res = testUploadPatch(t, test.URL+"/upload?key=k", 0, data[:4])
+	if res.StatusCode != 409 || res.Header.Get(proto.HeaderUploadOffset) != "4" {
+		t.Fatalf("expected offset conflict, got %d %v", res.StatusCode, res.Header)
+	}
+
+	res = testUploadPatch(t, test.URL+"/upload?key=k", 4, []byte("too long for the upload"))
+	if res.StatusCode != 413 {
+		t.Fatalf("expected length error, got %d", res.StatusCode)
+	}
+
+	res = testUploadPatch(t, test.URL+"/upload?key=other", 0, data)
+	if res.StatusCode != 404 {
+		t.Fatalf("expected unknown key, got %d", res.StatusCode)
+	}
+}
+
+func TestHandleUploadChecksum(t *testing.T) {
+	test := testUploadServer(t, testUploadHandler(t, []byte("0123456789"), nil, nil), 1024)
+
+	req, err := http.NewRequest("PATCH", test.URL+"/upload?key=k", bytes.NewReader([]byte("01234")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req.Header.Set("Content-Type", proto.ContentTypeUpload)
+	req.Header.Set(proto.HeaderUploadOffset, "0")
+	req.Header.Set(proto.HeaderUploadChecksum, proto.FormatUploadChecksum(testUploadSum([]byte("other"))))
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = res.Body.Close()
+
+	if res.StatusCode != 460 {
+		t.Fatalf("expected checksum mismatch, got %d", res.StatusCode)
+	}
+}
+
+func TestHandleUploadLimit(t *testing.T) {
+	data := bytes.Repeat([]byte("x"), 64)
+	test := testUploadServer(t, testUploadHandler(t, data, nil, nil), 16)
+
+	res := testUploadPatch(t, test.URL+"/upload?key=k", 0, data)
+	if res.StatusCode != 413 {
+		t.Fatalf("expected body over the upload limit to fail, got %d", res.StatusCode)
+	}
+}
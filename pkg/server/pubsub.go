@@ -0,0 +1,233 @@
+package server
+
+import (
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/heartbytenet/bblib/containers/sync"
+	"github.com/heartbytenet/go-lerpc/pkg/proto"
+)
+
+var (
+	ErrorTopicNotFound = "topic not found"
+)
+
+type Subscriber interface {
+	Deliver(event Event) error
+}
+
+type Event struct {
+	Id    uint64
+	Topic string
+	Data  map[string]any
+}
+
+type TopicRule struct {
+	pattern string
+	fnAuth  HandlerAuthFunction
+}
+
+type PubSub struct {
+	rules         *sync.Locked[[]TopicRule]
+	subscriptions *sync.Locked[map[Subscriber][]string]
+	replay        *sync.Locked[[]Event]
+	replayLimit   int
+	counter       atomic.Uint64
+	epoch         string
+}
+
+func NewPubSub(replayLimit int) *PubSub {
+	return &PubSub{
+		rules:         sync.NewLocked(make([]TopicRule, 0)),
+		subscriptions: sync.NewLocked(map[Subscriber][]string{}),
+		replay:        sync.NewLocked(make([]Event, 0, replayLimit)),
+		replayLimit:   replayLimit,
+		epoch:         strconv.FormatInt(time.Now().UnixNano(), 36),
+	}
+}
+
+func (pubsub *PubSub) GetEpoch() string {
+	return pubsub.epoch
+}
+
+func (pubsub *PubSub) FormatEventId(id uint64) string {
+	return pubsub.epoch + "-" + strconv.FormatUint(id, 10)
+}
+
+func (pubsub *PubSub) ParseEventId(value string) (id uint64, flag bool) {
+	var (
+		epoch string
+		err   error
+	)
+
+	epoch, value, flag = strings.Cut(value, "-")
+	if !flag || epoch != pubsub.epoch {
+		return 0, false
+	}
+
+	id, err = strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return
+}
+
+func (pubsub *PubSub) AddTopic(pattern string, fnAuth HandlerAuthFunction) {
+	pubsub.rules.Map(func(data []TopicRule) []TopicRule {
+		return append(data, TopicRule{
+			pattern: pattern,
+			fnAuth:  fnAuth,
+		})
+	})
+}
+
+func (pubsub *PubSub) HasTopics() (flag bool) {
+	pubsub.rules.Apply(func(data []TopicRule) {
+		flag = len(data) > 0
+	})
+
+	return
+}
+
+func (pubsub *PubSub) Auth(ctx *RequestContext, pattern string, token string) (result proto.Result) {
+	var (
+		rules []TopicRule
+	)
+
+	pubsub.rules.Apply(func(data []TopicRule) {
+		rules = append(rules, data...)
+	})
+
+	for _, rule := range rules {
+		if !proto.CoverTopic(rule.pattern, pattern) {
+			continue
+		}
+
+		if rule.fnAuth != nil && !rule.fnAuth(ctx, token) {
+			return proto.NewResult().
+				WithCode(proto.ResultCodeError).
+				WithMessage(ErrorAuthFailed)
+		}
+
+		return proto.NewResult().
+			WithCode(proto.ResultCodeSuccess)
+	}
+
+	return proto.NewResult().
+		WithCode(proto.ResultCodeError).
+		WithMessage(ErrorTopicNotFound)
+}
+
+func (pubsub *PubSub) Subscribe(subscriber Subscriber, pattern string) {
+	pubsub.subscriptions.Map(func(data map[Subscriber][]string) map[Subscriber][]string {
+		for _, value := range data[subscriber] {
+			if value == pattern {
+				return data
+			}
+		}
+
+		data[subscriber] = append(data[subscriber], pattern)
+		return data
+	})
+}
+
+func (pubsub *PubSub) Unsubscribe(subscriber Subscriber, pattern string) {
+	pubsub.subscriptions.Map(func(data map[Subscriber][]string) map[Subscriber][]string {
+		next := make([]string, 0)
+
+		for _, value := range data[subscriber] {
+			if value == pattern {
+				continue
+			}
+
+			next = append(next, value)
+		}
+
+		if len(next) < 1 {
+			delete(data, subscriber)
+			return data
+		}
+
+		data[subscriber] = next
+		return data
+	})
+}
+
+func (pubsub *PubSub) RemoveAll(subscriber Subscriber) {
+	pubsub.subscriptions.Map(func(data map[Subscriber][]string) map[Subscriber][]string {
+		delete(data, subscriber)
+		return data
+	})
+}
+
+func (pubsub *PubSub) Publish(topic string, data map[string]any) (count int) {
+	var (
+		event   Event
+		targets []Subscriber
+	)
+
+	event = Event{
+		Id:    pubsub.counter.Add(1),
+		Topic: topic,
+		Data:  data,
+	}
+
+	if pubsub.replayLimit > 0 {
+		pubsub.replay.Map(func(value []Event) []Event {
+			if len(value) >= pubsub.replayLimit {
+				value = value[1:]
+			}
+
+			return append(value, event)
+		})
+	}
+
+	pubsub.subscriptions.Apply(func(value map[Subscriber][]string) {
+		for subscriber, patterns := range value {
+			for _, pattern := range patterns {
+				if !proto.MatchTopic(pattern, topic) {
+					continue
+				}
+
+				targets = append(targets, subscriber)
+				break
+			}
+		}
+	})
+
+	for _, subscriber := range targets {
+		if subscriber.Deliver(event) != nil {
+			continue
+		}
+
+		count++
+	}
+
+	return
+}
+
+func (pubsub *PubSub) Replay(after uint64, patterns []string) (result []Event) {
+	result = make([]Event, 0)
+
+	pubsub.replay.Apply(func(value []Event) {
+		for _, event := range value {
+			if event.Id <= after {
+				continue
+			}
+
+			for _, pattern := range patterns {
+				if !proto.MatchTopic(pattern, event.Topic) {
+					continue
+				}
+
+				result = append(result, event)
+				break
+			}
+		}
+	})
+
+	return
+}
@@ -0,0 +1,71 @@
+package server
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPubSubPublishNonBlocking(t *testing.T) {
+	pubsub := NewPubSub(0)
+
+	slow := testConnection(1, SlowConsumerBlock)
+	fast := testConnection(4, SlowConsumerBlock)
+
+	pubsub.Subscribe(slow, "jobs.*")
+	pubsub.Subscribe(fast, "jobs.*")
+
+	if count := pubsub.Publish("jobs.a", nil); count != 2 {
+		t.Fatalf("first publish delivered to %d subscribers", count)
+	}
+
+	done := make(chan int, 1)
+	go func() {
+		done <- pubsub.Publish("jobs.b", nil)
+	}()
+
+	select {
+	case count := <-done:
+		if count != 1 {
+			t.Fatalf("expected delivery to the fast subscriber only, got %d", count)
+		}
+
+	case <-time.After(time.Second):
+		t.Fatal("publish blocked on a slow subscriber")
+	}
+
+	if len(fast.outgoing) != 2 {
+		t.Fatalf("fast subscriber received %d frames", len(fast.outgoing))
+	}
+}
+
+func TestPubSubEventId(t *testing.T) {
+	pubsub := NewPubSub(0)
+
+	id, flag := pubsub.ParseEventId(pubsub.FormatEventId(42))
+	if !flag || id != 42 {
+		t.Fatalf("round trip parsed %d, %v", id, flag)
+	}
+
+	for _, value := range []string{"", "42", "other-42", pubsub.GetEpoch() + "-x"} {
+		if id, flag = pubsub.ParseEventId(value); flag || id != 0 {
+			t.Fatalf("%q: expected a resync, got %d", value, id)
+		}
+	}
+}
+
+func TestPubSubReplayAfterRestart(t *testing.T) {
+	previous := NewPubSub(8)
+	for i := 0; i < 5; i++ {
+		previous.Publish("jobs.a", nil)
+	}
+
+	current := NewPubSub(8)
+	current.epoch = previous.epoch + "x"
+	current.Publish("jobs.a", map[string]any{"i": 1})
+	current.Publish("jobs.a", map[string]any{"i": 2})
+
+	lastId, _ := current.ParseEventId(previous.FormatEventId(5))
+	if events := current.Replay(lastId, []string{"jobs.*"}); len(events) != 2 {
+		t.Fatalf("expected a full replay after restart, got %d events", len(events))
+	}
+}
@@ -0,0 +1,141 @@
+package server
+
+import (
+	"errors"
+
+	"github.com/heartbytenet/bblib/containers/optionals"
+	"github.com/heartbytenet/bblib/containers/sync"
+)
+
+var (
+	ErrConnectionNotFound = errors.New("connection not found")
+)
+
+type ConnectionHookFunction func(connection *Connection)
+
+type ConnectionRegistry struct {
+	connections  *sync.Locked[map[string]*Connection]
+	onConnect    *sync.Locked[[]ConnectionHookFunction]
+	onDisconnect *sync.Locked[[]ConnectionHookFunction]
+}
+
+func NewConnectionRegistry() *ConnectionRegistry {
+	return &ConnectionRegistry{
+		connections:  sync.NewLocked(map[string]*Connection{}),
+		onConnect:    sync.NewLocked(make([]ConnectionHookFunction, 0)),
+		onDisconnect: sync.NewLocked(make([]ConnectionHookFunction, 0)),
+	}
+}
+
+func (registry *ConnectionRegistry) OnConnect(fn ConnectionHookFunction) {
+	registry.onConnect.Map(func(data []ConnectionHookFunction) []ConnectionHookFunction {
+		return append(data, fn)
+	})
+}
+
+func (registry *ConnectionRegistry) OnDisconnect(fn ConnectionHookFunction) {
+	registry.onDisconnect.Map(func(data []ConnectionHookFunction) []ConnectionHookFunction {
+		return append(data, fn)
+	})
+}
+
+func (registry *ConnectionRegistry) Add(connection *Connection) {
+	registry.connections.Map(func(data map[string]*Connection) map[string]*Connection {
+		data[connection.GetId()] = connection
+		return data
+	})
+
+	for _, fn := range registry.onConnect.Get() {
+		fn(connection)
+	}
+}
+
+func (registry *ConnectionRegistry) Remove(connection *Connection) {
+	registry.connections.Map(func(data map[string]*Connection) map[string]*Connection {
+		delete(data, connection.GetId())
+		return data
+	})
+
+	for _, fn := range registry.onDisconnect.Get() {
+		fn(connection)
+	}
+}
+
+func (registry *ConnectionRegistry) Get(id string) (result optionals.Optional[*Connection]) {
+	result = optionals.None[*Connection]()
+
+	registry.connections.Apply(func(data map[string]*Connection) {
+		connection, flag := data[id]
+		if !flag {
+			return
+		}
+
+		result = optionals.Some(connection)
+	})
+
+	return
+}
+
+func (registry *ConnectionRegistry) List() (result []*Connection) {
+	result = make([]*Connection, 0)
+
+	registry.connections.Apply(func(data map[string]*Connection) {
+		for _, connection := range data {
+			result = append(result, connection)
+		}
+	})
+
+	return
+}
+
+func (registry *ConnectionRegistry) ListByPrincipal(principal string) (result []*Connection) {
+	result = make([]*Connection, 0)
+
+	for _, connection := range registry.List() {
+		if connection.GetPrincipal() != principal {
+			continue
+		}
+
+		result = append(result, connection)
+	}
+
+	return
+}
+
+func (registry *ConnectionRegistry) Kick(id string) (err error) {
+	var (
+		connection *Connection
+	)
+
+	connection, err = registry.Get(id).GetTry()
+	if err != nil {
+		return ErrConnectionNotFound
+	}
+
+	return connection.Kick()
+}
+
+func (registry *ConnectionRegistry) Notify(id string, topic string, data map[string]any) (err error) {
+	var (
+		connection *Connection
+	)
+
+	connection, err = registry.Get(id).GetTry()
+	if err != nil {
+		return ErrConnectionNotFound
+	}
+
+	return connection.Notify(topic, data)
+}
+
+func (registry *ConnectionRegistry) NotifyPrincipal(principal string, topic string, data map[string]any) (count int) {
+	for _, connection := range registry.ListByPrincipal(principal) {
+		if connection.Notify(topic, data) != nil {
+			continue
+		}
+
+		count++
+	}
+
+	return
+}
@@ -1,15 +1,16 @@
 package server
 
 import (
-	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/gorilla/websocket"
-	"github.com/heartbytenet/bblib/collections/generic"
 	"github.com/heartbytenet/go-lerpc/pkg/client"
 
 	"github.com/gin-gonic/gin"
@@ -18,7 +19,11 @@ import (
 )
 
 var (
-	FallbackErrorMessage = "error"
+	FallbackErrorMessage  = "error"
+	ErrorMalformedFrame   = "malformed frame"
+	ErrorStreamInBatch    = "stream requests cannot be batched"
+	ErrorCodecUnsupported = "unsupported content type"
+	ErrorKeyInFlight      = "request key already in flight"
 )
 
 func init() {
@@ -40,49 +45,82 @@ type Server struct {
 
 func NewServer() *Server {
 	settings := NewSettingsDefault()
-	executor := NewExecutor(settings.ExecutorLimit)
+	executor := NewExecutorWithSettings(settings)
 
 	return &Server{
 		settings: settings,
 		executor: executor,
 
 		engine:   gin.New(),
-		upgrader: websocket.Upgrader{},
+		upgrader: settings.NewUpgrader(),
 	}
 }
 
 func NewServerWithSettings(settings Settings) *Server {
 	return &Server{
 		settings: settings,
-		executor: NewExecutor(settings.ExecutorLimit),
+		executor: NewExecutorWithSettings(settings),
 
 		engine:   gin.New(),
-		upgrader: websocket.Upgrader{},
+		upgrader: settings.NewUpgrader(),
 	}
 }
 
+func (server *Server) GetExecutor() *Executor {
+	return server.executor
+}
+
 func (server *Server) AddHandler(handler Handler) {
 	server.executor.AddHandler(handler)
 }
 
+func (server *Server) AddStreamHandler(handler StreamHandler) {
+	server.executor.AddStreamHandler(handler)
+}
+
 func (server *Server) AddDownloadHandler(handler DownloadHandler) {
 	server.executor.AddDownloadHandler(handler)
 }
 
+func (server *Server) AddUploadHandler(handler UploadHandler) {
+	server.executor.AddUploadHandler(handler)
+}
+
+func (server *Server) AddTopic(pattern string, fnAuth HandlerAuthFunction) {
+	server.executor.GetPubSub().AddTopic(pattern, fnAuth)
+}
+
+func (server *Server) Publish(topic string, data map[string]any) int {
+	return server.executor.GetPubSub().Publish(topic, data)
+}
+
+func (server *Server) OnConnect(fn ConnectionHookFunction) {
+	server.executor.GetRegistry().OnConnect(fn)
+}
+
+func (server *Server) OnDisconnect(fn ConnectionHookFunction) {
+	server.executor.GetRegistry().OnDisconnect(fn)
+}
+
+func (server *Server) GetConnections() []*Connection {
+	return server.executor.GetRegistry().List()
+}
+
 func (server *Server) Addr() string {
 	return fmt.Sprintf(":%d", server.settings.Port)
 }
 
+func (server *Server) GetEngine() *gin.Engine {
+	return server.engine
+}
+
 func (server *Server) Run() (err error) {
 	err = server.executor.Start(server.settings.ExecutorDelay)
 	if err != nil {
 		log.Fatalln("failed at starting executor:", err)
 	}
 
-	server.engine.GET("/connect", server.HandleConnect)
-	server.engine.POST("/execute", server.HandleExecute)
-
-	server.engine.GET("/download", server.HandleDownload)
+	server.Routes()
 
 	err = server.engine.Run(server.Addr())
 	if err != nil {
@@ -92,6 +130,22 @@ func (server *Server) Run() (err error) {
 	return
 }
 
+func (server *Server) Routes() {
+	server.engine.Use(server.HandleVersion)
+
+	server.engine.GET("/capabilities", server.HandleCapabilities)
+	server.engine.GET("/connect", server.HandleConnect)
+	server.engine.POST("/execute", server.HandleExecute)
+	server.engine.POST("/jsonrpc", server.HandleJsonRpc)
+
+	server.engine.GET("/events", server.HandleEvents)
+
+	server.engine.GET("/download", server.HandleDownload)
+
+	server.engine.HEAD("/upload", server.HandleUploadOffset)
+	server.engine.PATCH("/upload", server.HandleUploadPatch)
+}
+
 func (server *Server) ErrorResult(err string) (result proto.Result) {
 	result = proto.NewResult().
 		WithCode(proto.ResultCodeError)
@@ -103,176 +157,551 @@ func (server *Server) ErrorResult(err string) (result proto.Result) {
 	}
 }
 
+func (server *Server) RequestToken(ctx *gin.Context) string {
+	token, flag := strings.CutPrefix(ctx.GetHeader("Authorization"), "Bearer ")
+	if flag {
+		return token
+	}
+
+	return ctx.Query("t")
+}
+
+func (server *Server) RequestMode(ctx *gin.Context) client.ClientMode {
+	if ctx.Request.TLS != nil || ctx.Request.URL.Scheme == "https" {
+		return client.ClientModeHttps
+	}
+
+	return client.ClientModeHttp
+}
+
+func (server *Server) RequestCodec(ctx *gin.Context) (codec proto.Codec, err error) {
+	if ctx.GetHeader("Content-Type") == "" {
+		return proto.CodecDefault, nil
+	}
+
+	return proto.GetCodecByContentType(ctx.GetHeader("Content-Type")).
+		GetTry()
+}
+
+func (server *Server) Respond(ctx *gin.Context, codec proto.Codec, status int, value any) {
+	var (
+		data     []byte
+		encoding string
+		err      error
+	)
+
+	data, err = codec.Marshal(value)
+	if err != nil {
+		ctx.JSON(500, server.ErrorResult(err.Error()))
+		return
+	}
+
+	ctx.Header("Vary", "Accept-Encoding")
+
+	if server.settings.CompressionMinSize >= 0 && len(data) >= server.settings.CompressionMinSize {
+		encoding = proto.SelectEncoding(ctx.GetHeader("Accept-Encoding"), server.Encodings())
+	}
+
+	if encoding != "" {
+		data, err = proto.Compress(encoding, data)
+		if err != nil {
+			ctx.JSON(500, server.ErrorResult(err.Error()))
+			return
+		}
+
+		ctx.Header("Content-Encoding", encoding)
+	}
+
+	ctx.Data(status, codec.GetContentType(), data)
+}
+
+func (server *Server) RequestMeta(ctx *gin.Context, request proto.Request) proto.Request {
+	for name, values := range ctx.Request.Header {
+		key, flag := strings.CutPrefix(name, proto.HeaderMetaPrefix)
+		if !flag || key == "" {
+			continue
+		}
+
+		if request.GetMeta(key).IsPresent() {
+			continue
+		}
+
+		request = request.SetMeta(key, strings.Join(values, ", "))
+	}
+
+	return request
+}
+
+func (server *Server) ReadBody(ctx *gin.Context) (data []byte, err error) {
+	var (
+		reader io.Reader
+	)
+
+	reader = ctx.Request.Body
+	if server.settings.MaxMessageSize > 0 {
+		reader = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, server.settings.MaxMessageSize)
+	}
+
+	return io.ReadAll(reader)
+}
+
+func BodyStatus(err error) int {
+	var (
+		limit *http.MaxBytesError
+	)
+
+	if errors.As(err, &limit) {
+		return 413
+	}
+
+	return 500
+}
+
 func (server *Server) HandleExecute(ctx *gin.Context) {
 	var (
+		codec   proto.Codec
 		request proto.Request
-		promise *proto.Promise[proto.Result]
 		result  proto.Result
-		flag    bool
+		data    []byte
 		err     error
 	)
 
-	err = ctx.BindJSON(&request)
+	codec, err = server.RequestCodec(ctx)
 	if err != nil {
-		ctx.JSON(500, server.ErrorResult(err.Error()))
+		ctx.JSON(415, server.ErrorResult(ErrorCodecUnsupported))
 		return
 	}
 
-	mode := client.ClientModeHttp
-	if ctx.Request.URL.Scheme == "https" {
-		mode = client.ClientModeHttps
+	data, err = server.ReadBody(ctx)
+	if err != nil {
+		server.Respond(ctx, codec, BodyStatus(err), server.ErrorResult(err.Error()))
+		return
 	}
 
-	promise, flag = server.executor.PushRequest(mode, nil, request)
-	if !flag {
-		ctx.JSON(500, server.ErrorResult("executor queue is full"))
+	data, err = proto.Decompress(ctx.GetHeader("Content-Encoding"), data, server.settings.MaxMessageSize)
+	if errors.Is(err, proto.ErrEncodingUnsupported) {
+		server.Respond(ctx, codec, 415, server.ErrorResult(err.Error()))
+		return
+	}
+	if errors.Is(err, proto.ErrEncodingLimit) {
+		server.Respond(ctx, codec, 413, server.ErrorResult(err.Error()))
+		return
+	}
+	if err != nil {
+		server.Respond(ctx, codec, 400, server.ErrorResult(err.Error()))
 		return
 	}
 
-	result, err = promise.Await()
+	if codec.IsArray(data) {
+		server.HandleExecuteBatch(ctx, codec, data)
+		return
+	}
+
+	err = codec.Unmarshal(data, &request)
 	if err != nil {
-		ctx.JSON(500, server.ErrorResult(err.Error()))
+		server.Respond(ctx, codec, 500, server.ErrorResult(err.Error()))
 		return
 	}
 
-	ctx.JSON(200, result)
+	request = server.RequestMeta(ctx, request)
+
+	if request.GetStream() {
+		server.HandleStreamHttp(ctx, request)
+		return
+	}
+
+	if request.GetNoReply() {
+		_, flag := server.executor.PushRequest(server.RequestMode(ctx), nil, request)
+		if !flag {
+			server.Respond(ctx, codec, 500, server.ErrorResult(ErrorQueueFull).WithKey(request.GetKey()))
+			return
+		}
+
+		ctx.Status(202)
+		return
+	}
+
+	result, err = server.ExecuteHttp(server.RequestMode(ctx), request)
+	if err != nil {
+		server.Respond(ctx, codec, 500, server.ErrorResult(err.Error()).WithKey(request.GetKey()))
+		return
+	}
+
+	server.Respond(ctx, codec, 200, result)
 	return
 }
 
+func (server *Server) HandleExecuteBatch(ctx *gin.Context, codec proto.Codec, data []byte) {
+	var (
+		requests []proto.Request
+		results  []proto.Result
+		accepted []bool
+		keys     map[string]struct{}
+		group    sync.WaitGroup
+		err      error
+	)
+
+	err = codec.Unmarshal(data, &requests)
+	if err != nil {
+		server.Respond(ctx, codec, 500, server.ErrorResult(err.Error()))
+		return
+	}
+
+	mode := server.RequestMode(ctx)
+	results = make([]proto.Result, len(requests))
+	accepted = make([]bool, len(requests))
+	keys = make(map[string]struct{}, len(requests))
+
+	for index, request := range requests {
+		request = server.RequestMeta(ctx, request)
+
+		if _, flag := keys[request.GetKey()]; flag {
+			results[index] = proto.NewResult().
+				WithKey(request.GetKey()).
+				WithCode(proto.ResultCodeError).
+				WithMessage(ErrorKeyInFlight)
+			continue
+		}
+
+		if request.GetKey() != "" {
+			keys[request.GetKey()] = struct{}{}
+		}
+
+		if request.GetNoReply() {
+			_, flag := server.executor.PushRequest(mode, nil, request)
+			if !flag {
+				results[index] = server.ErrorResult(ErrorQueueFull).WithKey(request.GetKey())
+				continue
+			}
+
+			accepted[index] = true
+			continue
+		}
+
+		if request.GetStream() {
+			results[index] = proto.NewResult().
+				WithKey(request.GetKey()).
+				WithCode(proto.ResultCodeError).
+				WithMessage(ErrorStreamInBatch)
+			continue
+		}
+
+		group.Add(1)
+		go func(index int, request proto.Request) {
+			defer group.Done()
+
+			result, err := server.ExecuteHttp(mode, request)
+			if err != nil {
+				result = server.ErrorResult(err.Error())
+			}
+
+			if result.GetKey() == "" {
+				result = result.WithKey(request.GetKey())
+			}
+
+			results[index] = result
+		}(index, request)
+	}
+
+	group.Wait()
+
+	replies := make([]proto.Result, 0, len(results))
+	for index, result := range results {
+		if accepted[index] {
+			continue
+		}
+
+		replies = append(replies, result)
+	}
+
+	server.Respond(ctx, codec, 200, replies)
+}
+
+func (server *Server) ExecuteHttp(mode client.ClientMode, request proto.Request) (result proto.Result, err error) {
+	var (
+		promise *proto.Promise[proto.Result]
+		flag    bool
+	)
+
+	promise, flag = server.executor.PushRequest(mode, nil, request)
+	if !flag {
+		err = errors.New(ErrorQueueFull)
+		return
+	}
+
+	return promise.Await()
+}
+
 func (server *Server) HandleConnect(ctx *gin.Context) {
 	var (
-		writer  gin.ResponseWriter
-		request *http.Request
-		conn    *websocket.Conn
-		err     error
+		writer      gin.ResponseWriter
+		request     *http.Request
+		header      http.Header
+		subprotocol string
+		flag        bool
+		conn        *websocket.Conn
+		err         error
 	)
 
 	writer = ctx.Writer
 	request = ctx.Request
 
-	conn, err = server.upgrader.Upgrade(writer, request, nil)
+	subprotocol, flag = SelectSubprotocol(server.settings.Subprotocols, request)
+	if !flag {
+		ctx.JSON(400, server.ErrorResult(ErrorSubprotocolUnsupported))
+		return
+	}
+
+	header = http.Header{}
+	header.Set(proto.HeaderVersion, ctx.Writer.Header().Get(proto.HeaderVersion))
+	if subprotocol != "" {
+		header.Set("Sec-Websocket-Protocol", subprotocol)
+	}
+
+	conn, err = server.upgrader.Upgrade(writer, request, header)
 	if err != nil {
 		ctx.JSON(500, server.ErrorResult(err.Error()))
 		return
 	}
 
-	go server.HandleConnection(conn)
+	go server.HandleConnection(conn, ctx.ClientIP(), server.RequestToken(ctx))
 }
 
-func (server *Server) HandleConnection(conn *websocket.Conn) {
+func (server *Server) HandleConnection(conn *websocket.Conn, remoteAddr string, token string) {
 	var (
-		ctx      context.Context
-		cancel   context.CancelFunc
-		request  proto.Request
-		outgoing chan generic.Pair[int, []byte]
-		kind     int
-		data     []byte
-		err      error
+		connection *Connection
+		header     proto.FrameHeader
+		frame      proto.Frame
+		request    proto.Request
+		kind       int
+		data       []byte
+		err        error
 	)
 
-	ctx, cancel = context.WithCancel(context.Background())
-	outgoing = make(chan generic.Pair[int, []byte], 1024)
-
-	defer conn.Close()
-	defer cancel()
+	connection = NewConnection(conn, remoteAddr, server.settings)
+	connection.StartKeepalive(server.settings.MaxMessageSize)
+	server.executor.GetRegistry().Add(connection)
 
+	done := make(chan struct{})
 	go func() {
-		var (
-			err error
-		)
-
-		defer close(outgoing)
-
-		for {
-			select {
-			case <-ctx.Done():
-				{
-					return
-				}
-
-			case msg := <-outgoing:
-				{
-					err = conn.WriteMessage(msg.A(), msg.B())
-					if err != nil {
-						cancel()
-						return
-					}
-
-					break
-				}
-			}
-		}
+		defer close(done)
+		connection.LoopWrite()
 	}()
 
+	defer conn.Close()
+	defer connection.StopKeepalive()
+	defer func() { <-done }()
+	defer connection.Close()
+	defer server.executor.GetRegistry().Remove(connection)
+	defer server.executor.GetPubSub().RemoveAll(connection)
+
 	mode := client.ClientModeWss
+	jsonRpc := conn.Subprotocol() == proto.SubprotocolJsonRpc
 
 	for {
 		kind, data, err = conn.ReadMessage()
 		if err != nil {
 			break
 		}
-		_ = kind
+		connection.Touch()
 
-		err = json.Unmarshal(data, &request)
+		if jsonRpc {
+			go server.HandleJsonRpcWs(connection, token, data)
+			continue
+		}
+
+		if kind == websocket.BinaryMessage && len(data) > 0 && data[0] == proto.ChunkMarker {
+			server.SendFrameError(connection, nil, ErrorMalformedFrame)
+			continue
+		}
+
+		header = proto.FrameHeader{}
+		err = connection.GetCodec().Unmarshal(data, &header)
 		if err != nil {
-			break
+			server.SendFrameError(connection, data, ErrorMalformedFrame)
+			continue
+		}
+
+		if header.Kind != proto.FrameKindMessage {
+			frame = proto.Frame{}
+			err = connection.GetCodec().Unmarshal(data, &frame)
+			if err != nil {
+				server.SendFrameError(connection, data, ErrorMalformedFrame)
+				continue
+			}
+
+			server.HandleFrame(connection, frame)
+			continue
+		}
+
+		request = proto.Request{}
+		err = connection.GetCodec().Unmarshal(data, &request)
+		if err != nil {
+			server.SendFrameError(connection, data, ErrorMalformedFrame)
+			continue
+		}
+
+		if request.GetNoReply() {
+			_, _ = server.executor.PushRequest(mode, connection, request)
+			continue
+		}
+
+		if !connection.AcquireKey(request.GetKey()) {
+			_ = connection.SendValue(proto.NewResult().
+				WithKey(request.GetKey()).
+				WithCode(proto.ResultCodeError).
+				WithMessage(ErrorKeyInFlight))
+			continue
+		}
+
+		if request.GetStream() {
+			server.HandleStreamWs(connection, request)
+			continue
 		}
 
 		go func(request proto.Request) {
 			var (
 				promise *proto.Promise[proto.Result]
 				result  proto.Result
-				data    []byte
 				flag    bool
 				err     error
 			)
 
-			promise, flag = server.executor.PushRequest(mode, outgoing, request)
+			defer connection.ReleaseKey(request.GetKey())
+
+			promise, flag = server.executor.PushRequest(mode, connection, request)
 			if !flag {
+				_ = connection.SendValue(proto.NewResult().
+					WithKey(request.GetKey()).
+					WithCode(proto.ResultCodeError).
+					WithMessage(ErrorQueueFull))
 				return
 			}
 
 			result, err = promise.Await()
-			if err != nil {
+			if errors.Is(err, proto.ErrCancelled) {
+				_ = connection.SendValue(proto.NewResult().
+					WithKey(request.GetKey()).
+					WithCode(proto.ResultCodeError).
+					WithMessage(err.Error()))
 				return
 			}
-
-			data, err = json.Marshal(result)
 			if err != nil {
+				_ = connection.SendValue(server.ErrorResult(err.Error()).
+					WithKey(request.GetKey()))
 				return
 			}
 
-			select {
-			case <-ctx.Done():
-				return
-
-			default:
-				outgoing <- generic.NewPair(websocket.TextMessage, data)
-			}
+			_ = connection.SendValue(result)
 		}(request)
 	}
 }
 
+func (server *Server) SendFrameError(connection *Connection, data []byte, message string) {
+	var (
+		value struct {
+			Key string `json:"k"`
+		}
+	)
+
+	_ = connection.GetCodec().Unmarshal(data, &value)
+
+	_ = connection.SendValue(proto.NewResult().
+		WithKey(value.Key).
+		WithCode(proto.ResultCodeError).
+		WithMessage(message))
+}
+
+func (server *Server) HandleFrame(connection *Connection, frame proto.Frame) {
+	switch frame.GetKind() {
+	case proto.FrameKindTransferRequest:
+		go server.HandleTransfer(connection, frame)
+
+	case proto.FrameKindSubscribe:
+		server.HandleSubscribe(connection, frame)
+
+	case proto.FrameKindUnsubscribe:
+		server.executor.GetPubSub().Unsubscribe(connection, frame.GetTopic())
+
+		_ = connection.SendValue(proto.NewResult().
+			WithKey(frame.GetKey()).
+			WithCode(proto.ResultCodeSuccess))
+
+	case proto.FrameKindCancel:
+		server.executor.CancelRequest(connection, frame.GetKey())
+
+	case proto.FrameKindHello:
+		server.HandleHello(connection, frame)
+
+	case proto.FrameKindStreamData:
+		connection.GetStream(frame.GetKey()).IfPresent(func(stream *Stream) {
+			_ = stream.Push(frame.GetDataAll())
+		})
+
+	case proto.FrameKindStreamEnd, proto.FrameKindStreamError:
+		connection.GetStream(frame.GetKey()).IfPresent(func(stream *Stream) {
+			stream.CloseRecv()
+		})
+
+	case proto.FrameKindTransferAck:
+		connection.GetTransfer(frame.GetKey()).IfPresent(func(transfer *Transfer) {
+			transfer.Ack(frame.GetSeq())
+		})
+
+	case proto.FrameKindTransferError:
+		connection.GetTransfer(frame.GetKey()).IfPresent(func(transfer *Transfer) {
+			transfer.Abort()
+		})
+	}
+}
+
+func (server *Server) HandleSubscribe(connection *Connection, frame proto.Frame) {
+	var (
+		request proto.Request
+		ctx     *RequestContext
+		result  proto.Result
+	)
+
+	request = proto.NewRequest().
+		WithToken(frame.GetToken()).
+		WithKey(frame.GetKey()).
+		WithMethod(frame.GetTopic())
+
+	ctx = NewRequestContext(server.executor, client.ClientModeWss, connection, request)
+
+	result = server.executor.GetPubSub().Auth(ctx, frame.GetTopic(), frame.GetToken())
+	if result.GetCode() == proto.ResultCodeSuccess {
+		server.executor.GetPubSub().Subscribe(connection, frame.GetTopic())
+	}
+
+	_ = connection.SendValue(result.WithKey(frame.GetKey()))
+}
+
 func (server *Server) HandleDownload(ctx *gin.Context) {
 	var (
 		key string
+		err error
 	)
 
-	key = ctx.Query("key")
+	key, err = server.executor.GetSigner().Verify(ctx.Request.URL.Query(), server.RequestToken(ctx))
+	if err != nil {
+		ctx.JSON(403, proto.NewResult().
+			WithCode(proto.ResultCodeError).
+			WithMessage(err.Error()))
+		return
+	}
 
 	server.executor.GetDownloadHandlerAlive(key).
 		IfPresentElse(
 			func(handler DownloadHandler) {
 				reader, err := handler.Pull()
-				if err != nil {
-					ctx.JSON(500, proto.NewResult().
+				if errors.Is(err, ErrDownloadRemoved) {
+					ctx.JSON(410, proto.NewResult().
 						WithCode(proto.ResultCodeError).
 						WithMessage(err.Error()))
 
 					return
 				}
-
-				data, err := io.ReadAll(reader)
 				if err != nil {
 					ctx.JSON(500, proto.NewResult().
 						WithCode(proto.ResultCodeError).
@@ -281,7 +710,11 @@ func (server *Server) HandleDownload(ctx *gin.Context) {
 					return
 				}
 
-				ctx.Data(200, handler.GetContentType(), data)
+				if closer, flag := reader.(io.Closer); flag {
+					defer closer.Close()
+				}
+
+				ctx.DataFromReader(200, -1, handler.GetContentType(), reader, nil)
 				return
 			},
 			func() {
@@ -290,3 +723,103 @@ func (server *Server) HandleDownload(ctx *gin.Context) {
 					WithMessage("handler not found"))
 			})
 }
+
+func (server *Server) HandleUploadOffset(ctx *gin.Context) {
+	var (
+		key string
+	)
+
+	key = ctx.Query("key")
+
+	server.executor.GetUploadHandlerAlive(key).
+		IfPresentElse(
+			func(handler UploadHandler) {
+				offset, err := handler.GetOffset()
+				if err != nil {
+					ctx.Status(500)
+					return
+				}
+
+				ctx.Header("Cache-Control", "no-store")
+				ctx.Header(proto.HeaderUploadOffset, strconv.FormatInt(offset, 10))
+				ctx.Header(proto.HeaderUploadLength, strconv.FormatInt(handler.GetLength(), 10))
+				ctx.Status(200)
+			},
+			func() {
+				ctx.Status(404)
+			})
+}
+
+func (server *Server) HandleUploadPatch(ctx *gin.Context) {
+	var (
+		key string
+	)
+
+	key = ctx.Query("key")
+
+	server.executor.GetUploadHandlerAlive(key).
+		IfPresentElse(
+			func(handler UploadHandler) {
+				var (
+					offset   int64
+					chunkSum []byte
+					fileSum  []byte
+					data     []byte
+					err      error
+				)
+
+				if ctx.ContentType() != proto.ContentTypeUpload {
+					ctx.JSON(415, server.ErrorResult("invalid content type"))
+					return
+				}
+
+				offset, err = strconv.ParseInt(ctx.GetHeader(proto.HeaderUploadOffset), 10, 64)
+				if err != nil {
+					ctx.JSON(400, server.ErrorResult(err.Error()))
+					return
+				}
+
+				chunkSum, err = proto.ParseUploadChecksum(ctx.GetHeader(proto.HeaderUploadChecksum))
+				if err != nil {
+					ctx.JSON(400, server.ErrorResult(err.Error()))
+					return
+				}
+
+				fileSum, err = proto.ParseUploadChecksum(ctx.GetHeader(proto.HeaderUploadDigest))
+				if err != nil {
+					ctx.JSON(400, server.ErrorResult(err.Error()))
+					return
+				}
+
+				data, err = io.ReadAll(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, server.settings.UploadLimit))
+				if err != nil {
+					ctx.JSON(413, server.ErrorResult(err.Error()))
+					return
+				}
+
+				offset, err = handler.Write(offset, data, chunkSum, fileSum)
+				ctx.Header(proto.HeaderUploadOffset, strconv.FormatInt(offset, 10))
+
+				switch {
+				case err == nil:
+					ctx.Status(204)
+
+				case errors.Is(err, ErrUploadOffset), errors.Is(err, ErrUploadComplete):
+					ctx.JSON(409, server.ErrorResult(err.Error()))
+
+				case errors.Is(err, ErrUploadLength):
+					ctx.JSON(413, server.ErrorResult(err.Error()))
+
+				case errors.Is(err, ErrUploadChecksum):
+					ctx.JSON(460, server.ErrorResult(err.Error()))
+
+				default:
+					ctx.JSON(500, server.ErrorResult(err.Error()))
+				}
+			},
+			func() {
+				ctx.JSON(404, proto.NewResult().
+					WithCode(proto.ResultCodeError).
+					WithMessage("handler not found"))
+			})
+}
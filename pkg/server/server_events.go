@@ -0,0 +1,266 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goccy/go-json"
+	"github.com/heartbytenet/go-lerpc/pkg/client"
+	"github.com/heartbytenet/go-lerpc/pkg/proto"
+)
+
+const (
+	ContentTypeEventStream = "text/event-stream"
+
+	EventNameData  = "data"
+	EventNameEnd   = "end"
+	EventNameError = "error"
+)
+
+type EventSubscriber struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	events chan Event
+}
+
+func NewEventSubscriber(ctx context.Context, buffer int) *EventSubscriber {
+	ctx, cancel := context.WithCancel(ctx)
+
+	return &EventSubscriber{
+		ctx:    ctx,
+		cancel: cancel,
+		events: make(chan Event, buffer),
+	}
+}
+
+func (subscriber *EventSubscriber) Done() <-chan struct{} {
+	return subscriber.ctx.Done()
+}
+
+func (subscriber *EventSubscriber) Close() {
+	subscriber.cancel()
+}
+
+func (subscriber *EventSubscriber) Deliver(event Event) error {
+	select {
+	case <-subscriber.ctx.Done():
+		return ErrConnectionClosed
+
+	case subscriber.events <- event:
+		return nil
+
+	default:
+		subscriber.Close()
+		return ErrSlowConsumer
+	}
+}
+
+func WriteEvent(writer io.Writer, id string, name string, value any) (err error) {
+	var (
+		data []byte
+	)
+
+	data, err = json.Marshal(value)
+	if err != nil {
+		return
+	}
+
+	if id != "" {
+		_, err = fmt.Fprintf(writer, "id: %s\n", id)
+		if err != nil {
+			return
+		}
+	}
+
+	_, err = fmt.Fprintf(writer, "event: %s\ndata: %s\n\n", name, data)
+	return
+}
+
+func (server *Server) WriteEventsHeader(ctx *gin.Context) {
+	ctx.Header("Content-Type", ContentTypeEventStream)
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.Status(200)
+	ctx.Writer.Flush()
+}
+
+func (server *Server) HandleEvents(ctx *gin.Context) {
+	if ctx.Query("n") != "" {
+		server.HandleEventsStream(ctx)
+		return
+	}
+
+	server.HandleEventsTopics(ctx)
+}
+
+func (server *Server) HandleEventsTopics(ctx *gin.Context) {
+	var (
+		token      string
+		patterns   []string
+		lastId     uint64
+		subscriber *EventSubscriber
+		request    proto.Request
+		result     proto.Result
+		ticker     *time.Ticker
+		ping       <-chan time.Time
+		err        error
+	)
+
+	token = server.RequestToken(ctx)
+	patterns = ctx.QueryArray("topic")
+	if len(patterns) < 1 {
+		ctx.JSON(400, server.ErrorResult("no topics requested"))
+		return
+	}
+
+	for _, pattern := range patterns {
+		request = proto.NewRequest().
+			WithToken(token).
+			WithMethod(pattern)
+
+		result = server.executor.GetPubSub().Auth(
+			NewRequestContext(server.executor, client.ClientModeHttp, nil, request),
+			pattern,
+			token)
+		if result.GetCode() != proto.ResultCodeSuccess {
+			ctx.JSON(403, result)
+			return
+		}
+	}
+
+	lastId, _ = server.executor.GetPubSub().ParseEventId(ctx.GetHeader("Last-Event-ID"))
+
+	subscriber = NewEventSubscriber(ctx.Request.Context(), server.settings.EventsBuffer)
+	for _, pattern := range patterns {
+		server.executor.GetPubSub().Subscribe(subscriber, pattern)
+	}
+	defer server.executor.GetPubSub().RemoveAll(subscriber)
+	defer subscriber.Close()
+
+	server.WriteEventsHeader(ctx)
+
+	for _, event := range server.executor.GetPubSub().Replay(lastId, patterns) {
+		err = WriteEvent(ctx.Writer, server.executor.GetPubSub().FormatEventId(event.Id), event.Topic, event.Data)
+		if err != nil {
+			return
+		}
+
+		lastId = event.Id
+	}
+	ctx.Writer.Flush()
+
+	if server.settings.PingInterval > 0 {
+		ticker = time.NewTicker(server.settings.PingInterval)
+		defer ticker.Stop()
+
+		ping = ticker.C
+	}
+
+	for {
+		select {
+		case <-subscriber.Done():
+			return
+
+		case <-ping:
+			_, err = io.WriteString(ctx.Writer, ": ping\n\n")
+			if err != nil {
+				return
+			}
+
+			ctx.Writer.Flush()
+
+		case event := <-subscriber.events:
+			if event.Id <= lastId {
+				continue
+			}
+
+			err = WriteEvent(ctx.Writer, server.executor.GetPubSub().FormatEventId(event.Id), event.Topic, event.Data)
+			if err != nil {
+				return
+			}
+
+			ctx.Writer.Flush()
+		}
+	}
+}
+
+func (server *Server) HandleEventsStream(ctx *gin.Context) {
+	var (
+		request        proto.Request
+		requestContext *RequestContext
+		stream         *Stream
+		params         map[string]any
+		seq            uint64
+		mutex          sync.Mutex
+		err            error
+	)
+
+	if value := ctx.Query("p"); value != "" {
+		err = json.Unmarshal([]byte(value), &params)
+		if err != nil {
+			ctx.JSON(400, server.ErrorResult(err.Error()))
+			return
+		}
+	}
+
+	request = proto.NewRequest().
+		WithToken(server.RequestToken(ctx)).
+		WithKey(ctx.Query("k")).
+		WithNamespace(ctx.Query("n")).
+		WithMethod(ctx.Query("m")).
+		WithParams(params).
+		WithStream(true)
+
+	request = server.RequestMeta(ctx, request)
+
+	requestContext = NewRequestContext(server.executor, client.ClientModeHttp, nil, request)
+	stream = NewStream(requestContext.Context(), request.GetKey(), 0, func(frame proto.Frame) (err error) {
+		var (
+			name string
+		)
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		switch frame.GetKind() {
+		case proto.FrameKindStreamData:
+			name = EventNameData
+
+		case proto.FrameKindStreamEnd:
+			name = EventNameEnd
+
+		default:
+			name = EventNameError
+		}
+
+		seq++
+
+		err = WriteEvent(ctx.Writer, strconv.FormatUint(seq, 10), name, frame)
+		if err != nil {
+			requestContext.Cancel()
+			return
+		}
+
+		ctx.Writer.Flush()
+		return
+	})
+	stream.CloseRecv()
+
+	go func() {
+		select {
+		case <-ctx.Request.Context().Done():
+			requestContext.Cancel()
+
+		case <-requestContext.Done():
+		}
+	}()
+
+	server.WriteEventsHeader(ctx)
+
+	_ = stream.End(server.executor.ExecuteStream(requestContext, stream))
+}
@@ -0,0 +1,195 @@
+package server
+
+import (
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goccy/go-json"
+	"github.com/heartbytenet/go-lerpc/pkg/client"
+	"github.com/heartbytenet/go-lerpc/pkg/proto"
+)
+
+func (server *Server) HandleJsonRpc(ctx *gin.Context) {
+	var (
+		data  []byte
+		reply any
+		flag  bool
+		err   error
+	)
+
+	data, err = server.ReadBody(ctx)
+	if err != nil {
+		ctx.JSON(BodyStatus(err), proto.NewJsonRpcResponse(nil).
+			WithError(proto.NewJsonRpcError(proto.JsonRpcCodeInternalError, err.Error())))
+		return
+	}
+
+	reply, flag = server.ExecuteJsonRpcData(server.RequestMode(ctx), nil, server.RequestToken(ctx), data)
+	if !flag {
+		ctx.Status(204)
+		return
+	}
+
+	ctx.JSON(200, reply)
+}
+
+func (server *Server) HandleJsonRpcWs(connection *Connection, token string, data []byte) {
+	reply, flag := server.ExecuteJsonRpcData(client.ClientModeWss, connection, token, data)
+	if !flag {
+		return
+	}
+
+	_ = connection.SendValue(reply)
+}
+
+func (server *Server) ExecuteJsonRpcData(mode client.ClientMode, connection *Connection, token string, data []byte) (reply any, flag bool) {
+	var (
+		request   proto.JsonRpcRequest
+		messages  []json.RawMessage
+		responses []proto.JsonRpcResponse
+		replies   []bool
+		group     sync.WaitGroup
+		err       error
+	)
+
+	if !(proto.CodecJson{}).IsArray(data) {
+		err = json.Unmarshal(data, &request)
+		if err != nil {
+			return proto.NewJsonRpcResponse(nil).
+				WithError(proto.NewJsonRpcError(proto.JsonRpcCodeParseError, err.Error())), true
+		}
+
+		return server.ExecuteJsonRpc(mode, connection, token, request)
+	}
+
+	err = json.Unmarshal(data, &messages)
+	if err != nil {
+		return proto.NewJsonRpcResponse(nil).
+			WithError(proto.NewJsonRpcError(proto.JsonRpcCodeParseError, err.Error())), true
+	}
+
+	if len(messages) < 1 {
+		return proto.NewJsonRpcResponse(nil).
+			WithError(proto.NewJsonRpcError(proto.JsonRpcCodeInvalidRequest, "empty batch")), true
+	}
+
+	responses = make([]proto.JsonRpcResponse, len(messages))
+	replies = make([]bool, len(messages))
+
+	for index, message := range messages {
+		group.Add(1)
+		go func(index int, message json.RawMessage) {
+			defer group.Done()
+
+			var request proto.JsonRpcRequest
+
+			err := json.Unmarshal(message, &request)
+			if err != nil {
+				responses[index] = proto.NewJsonRpcResponse(nil).
+					WithError(proto.NewJsonRpcError(proto.JsonRpcCodeInvalidRequest, err.Error()))
+				replies[index] = true
+				return
+			}
+
+			response, flag := server.ExecuteJsonRpc(mode, connection, token, request)
+			responses[index], replies[index] = response, flag
+		}(index, message)
+	}
+
+	group.Wait()
+
+	result := make([]proto.JsonRpcResponse, 0, len(responses))
+	for index, response := range responses {
+		if !replies[index] {
+			continue
+		}
+
+		result = append(result, response)
+	}
+
+	if len(result) < 1 {
+		return
+	}
+
+	return result, true
+}
+
+func (server *Server) ExecuteJsonRpc(mode client.ClientMode, connection *Connection, token string, value proto.JsonRpcRequest) (response proto.JsonRpcResponse, flag bool) {
+	var (
+		request proto.Request
+		promise *proto.Promise[proto.Result]
+		result  proto.Result
+		queued  bool
+		err     error
+	)
+
+	response = proto.NewJsonRpcResponse(value.Id)
+	flag = !value.IsNotification()
+
+	request, err = value.ToRequest(token)
+	if errors.Is(err, proto.ErrJsonRpcParams) {
+		response = response.WithError(proto.NewJsonRpcError(proto.JsonRpcCodeInvalidParams, err.Error()))
+		return
+	}
+	if err != nil {
+		response = response.WithError(proto.NewJsonRpcError(proto.JsonRpcCodeInvalidRequest, err.Error()))
+		return
+	}
+
+	promise, queued = server.executor.PushRequest(mode, connection, request)
+	if !queued {
+		response = response.WithError(proto.NewJsonRpcError(proto.JsonRpcCodeServerError, ErrorQueueFull))
+		return
+	}
+
+	if !flag {
+		return
+	}
+
+	result, err = promise.Await()
+	if errors.Is(err, proto.ErrCancelled) {
+		response = response.WithError(proto.NewJsonRpcError(proto.JsonRpcCodeServerError, err.Error()))
+		return
+	}
+	if err != nil {
+		response = response.WithError(proto.NewJsonRpcError(proto.JsonRpcCodeInternalError, server.ErrorResult(err.Error()).GetMessage()))
+		return
+	}
+
+	response = JsonRpcFromResult(response, result)
+	return
+}
+
+func JsonRpcCode(message string) int {
+	switch {
+	case message == ErrorHandlerNotFound:
+		return proto.JsonRpcCodeMethodNotFound
+
+	case message == ErrorInvalidParams:
+		return proto.JsonRpcCodeInvalidParams
+
+	case message == ErrorAuthFailed:
+		return proto.JsonRpcCodeAuthFailed
+
+	case strings.HasPrefix(message, proto.ErrVersionUnsupported.Error()):
+		return proto.JsonRpcCodeVersionUnsupported
+
+	default:
+		return proto.JsonRpcCodeServerError
+	}
+}
+
+func JsonRpcFromResult(response proto.JsonRpcResponse, result proto.Result) proto.JsonRpcResponse {
+	if result.GetCode() == proto.ResultCodeError {
+		return response.WithError(proto.NewJsonRpcError(JsonRpcCode(result.GetMessage()), result.GetMessage()))
+	}
+
+	data := result.GetDataAll()
+	if data == nil {
+		data = map[string]any{}
+	}
+
+	return response.WithResult(data)
+}
@@ -0,0 +1,48 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/heartbytenet/go-lerpc/pkg/proto"
+)
+
+func TestJsonRpcCode(t *testing.T) {
+	cases := map[string]int{
+		ErrorHandlerNotFound: proto.JsonRpcCodeMethodNotFound,
+		ErrorInvalidParams:   proto.JsonRpcCodeInvalidParams,
+		ErrorAuthFailed:      proto.JsonRpcCodeAuthFailed,
+		fmt.Errorf("%w: version 9", proto.ErrVersionUnsupported).Error(): proto.JsonRpcCodeVersionUnsupported,
+		"anything else": proto.JsonRpcCodeServerError,
+	}
+
+	for message, expected := range cases {
+		if code := JsonRpcCode(message); code != expected {
+			t.Fatalf("%q mapped to %d, expected %d", message, code, expected)
+		}
+	}
+}
+
+func TestHandleJsonRpcLimit(t *testing.T) {
+	settings := NewSettingsDefault()
+	settings.MaxMessageSize = 64
+
+	server := NewServerWithSettings(settings)
+	server.Routes()
+
+	test := httptest.NewServer(server.GetEngine())
+	t.Cleanup(test.Close)
+
+	res, err := http.Post(test.URL+"/jsonrpc", "application/json", bytes.NewReader(bytes.Repeat([]byte(" "), 128)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = res.Body.Close()
+
+	if res.StatusCode != 413 {
+		t.Fatalf("expected body over the message limit to fail, got %d", res.StatusCode)
+	}
+}
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goccy/go-json"
+	"github.com/heartbytenet/go-lerpc/pkg/client"
+	"github.com/heartbytenet/go-lerpc/pkg/proto"
+)
+
+const (
+	ContentTypeNdjson = "application/x-ndjson"
+)
+
+func (server *Server) HandleStreamWs(connection *Connection, request proto.Request) {
+	var (
+		ctx    *RequestContext
+		stream *Stream
+	)
+
+	ctx = NewRequestContext(server.executor, client.ClientModeWss, connection, request)
+	stream = NewStream(ctx.Context(), request.GetKey(), max(server.settings.StreamBuffer, 1), connection.SendFrame)
+
+	connection.AddStream(stream)
+	_ = stream.Grant()
+
+	go func() {
+		defer connection.ReleaseKey(stream.GetKey())
+		defer connection.RemoveStream(stream.GetKey())
+
+		_ = stream.End(server.executor.ExecuteStream(ctx, stream))
+	}()
+}
+
+func (server *Server) HandleStreamHttp(ctx *gin.Context, request proto.Request) {
+	var (
+		requestContext *RequestContext
+		stream         *Stream
+		mutex          sync.Mutex
+	)
+
+	requestContext = NewRequestContext(server.executor, server.RequestMode(ctx), nil, request)
+	stream = NewStream(requestContext.Context(), request.GetKey(), 0, func(frame proto.Frame) (err error) {
+		var (
+			data []byte
+		)
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		data, err = json.Marshal(frame)
+		if err != nil {
+			return
+		}
+
+		_, err = ctx.Writer.Write(append(data, '\n'))
+		if err != nil {
+			requestContext.Cancel()
+			return
+		}
+
+		ctx.Writer.Flush()
+		return
+	})
+	stream.CloseRecv()
+
+	go func() {
+		select {
+		case <-ctx.Request.Context().Done():
+			requestContext.Cancel()
+
+		case <-requestContext.Done():
+		}
+	}()
+
+	ctx.Header("Content-Type", ContentTypeNdjson)
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Status(200)
+
+	_ = stream.End(server.executor.ExecuteStream(requestContext, stream))
+}
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/heartbytenet/go-lerpc/pkg/proto"
+)
+
+func TestHandleExecuteBatchDuplicateKey(t *testing.T) {
+	server := NewServer()
+	server.AddHandler(NewHandlerWith("test", "echo", nil, func(ctx *RequestContext, request proto.Request) proto.Result {
+		return proto.NewResult().WithCode(proto.ResultCodeSuccess)
+	}))
+	server.Routes()
+
+	if err := server.GetExecutor().Start(time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+
+	test := httptest.NewServer(server.GetEngine())
+	t.Cleanup(test.Close)
+
+	requests := []proto.Request{
+		proto.NewRequest().WithKey("a").WithNamespace("test").WithMethod("echo"),
+		proto.NewRequest().WithKey("a").WithNamespace("test").WithMethod("echo"),
+		proto.NewRequest().WithNamespace("test").WithMethod("echo"),
+		proto.NewRequest().WithNamespace("test").WithMethod("echo"),
+	}
+
+	data, err := json.Marshal(requests)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := http.Post(test.URL+"/execute", "application/json", bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	var results []proto.Result
+	if err = json.NewDecoder(res.Body).Decode(&results); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 4 {
+		t.Fatalf("expected 4 results, got %d", len(results))
+	}
+
+	expected := []proto.ResultCode{proto.ResultCodeSuccess, proto.ResultCodeError, proto.ResultCodeSuccess, proto.ResultCodeSuccess}
+	for index, code := range expected {
+		if results[index].GetCode() != code {
+			t.Fatalf("result %d: unexpected %+v", index, results[index])
+		}
+	}
+
+	if results[1].GetMessage() != ErrorKeyInFlight {
+		t.Fatalf("unexpected duplicate message %q", results[1].GetMessage())
+	}
+}
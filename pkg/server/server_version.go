@@ -0,0 +1,126 @@
+package server
+
+import (
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"github.com/heartbytenet/go-lerpc/pkg/proto"
+)
+
+const (
+	ContextKeyVersion = "lerpc.version"
+)
+
+func (server *Server) Capabilities() proto.Capabilities {
+	capabilities := proto.NewCapabilities()
+	capabilities.Subprotocols = slices.Clone(server.settings.Subprotocols)
+	capabilities.Encodings = server.Encodings()
+	capabilities.Features = server.Features()
+
+	return capabilities
+}
+
+func (server *Server) Encodings() []string {
+	if server.settings.CompressionMinSize < 0 {
+		return nil
+	}
+
+	return slices.Clone(proto.Encodings)
+}
+
+func (server *Server) Features() (features []string) {
+	features = []string{
+		proto.FeatureBatch,
+		proto.FeatureNoReply,
+		proto.FeatureMeta,
+		proto.FeatureJsonRpc,
+	}
+
+	if server.executor.HasStreamHandlers() {
+		features = append(features, proto.FeatureStream)
+	}
+
+	if server.executor.GetPubSub().HasTopics() {
+		features = append(features, proto.FeatureEvents)
+	}
+
+	if server.settings.TransferWindow > 0 && server.settings.TransferChunkSize > 0 {
+		features = append(features, proto.FeatureTransfer)
+	}
+
+	if server.settings.UploadLimit > 0 {
+		features = append(features, proto.FeatureUpload)
+	}
+
+	return
+}
+
+func (server *Server) HandleVersion(ctx *gin.Context) {
+	var (
+		low     uint32
+		high    uint32
+		version uint32
+		err     error
+	)
+
+	if ctx.GetHeader(proto.HeaderVersion) == "" {
+		ctx.Header(proto.HeaderVersion, proto.VersionRange())
+		ctx.Next()
+		return
+	}
+
+	low, high, err = proto.ParseVersionRange(ctx.GetHeader(proto.HeaderVersion))
+	if err == nil {
+		version, err = proto.NegotiateVersion(low, high)
+	}
+	if err != nil {
+		ctx.Header(proto.HeaderVersion, proto.VersionRange())
+		ctx.AbortWithStatusJSON(400, proto.NewResult().
+			WithCode(proto.ResultCodeError).
+			WithMessage(err.Error()))
+		return
+	}
+
+	ctx.Set(ContextKeyVersion, version)
+	ctx.Header(proto.HeaderVersion, proto.FormatVersionRange(version, version))
+	ctx.Next()
+}
+
+func (server *Server) HandleCapabilities(ctx *gin.Context) {
+	capabilities := server.Capabilities()
+
+	version, flag := ctx.Get(ContextKeyVersion)
+	if flag {
+		capabilities.Negotiated = version.(uint32)
+	}
+
+	ctx.JSON(200, capabilities)
+}
+
+func (server *Server) HandleHello(connection *Connection, frame proto.Frame) {
+	var (
+		capabilities proto.Capabilities
+		version      uint32
+		err          error
+	)
+
+	capabilities, err = proto.CapabilitiesFromData(frame.GetDataAll())
+	if err == nil {
+		version, err = proto.NegotiateVersion(capabilities.VersionMin, capabilities.Version)
+	}
+	if err != nil {
+		_ = connection.SendValue(proto.NewResult().
+			WithKey(frame.GetKey()).
+			WithCode(proto.ResultCodeError).
+			WithMessage(err.Error()))
+		return
+	}
+
+	capabilities = server.Capabilities()
+	capabilities.Negotiated = version
+
+	_ = connection.SendValue(proto.NewResult().
+		WithKey(frame.GetKey()).
+		WithCode(proto.ResultCodeSuccess).
+		WithData(capabilities.ToData()))
+}
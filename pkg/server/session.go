@@ -0,0 +1,73 @@
+package server
+
+import (
+	"github.com/heartbytenet/bblib/containers/optionals"
+	"github.com/heartbytenet/bblib/containers/sync"
+)
+
+type Session struct {
+	values *sync.Locked[map[string]any]
+}
+
+func NewSession() *Session {
+	return &Session{
+		values: sync.NewLocked(map[string]any{}),
+	}
+}
+
+func (session *Session) Set(key string, value any) {
+	session.values.Map(func(data map[string]any) map[string]any {
+		data[key] = value
+		return data
+	})
+}
+
+func (session *Session) Get(key string) (result optionals.Optional[any]) {
+	result = optionals.None[any]()
+
+	session.values.Apply(func(data map[string]any) {
+		value, flag := data[key]
+		if !flag {
+			return
+		}
+
+		result = optionals.Some(value)
+	})
+
+	return
+}
+
+func (session *Session) Delete(key string) {
+	session.values.Map(func(data map[string]any) map[string]any {
+		delete(data, key)
+		return data
+	})
+}
+
+func (session *Session) Keys() (keys []string) {
+	session.values.Apply(func(data map[string]any) {
+		for key := range data {
+			keys = append(keys, key)
+		}
+	})
+
+	return
+}
+
+func GetSessionValue[T any](session *Session, key string) optionals.Optional[T] {
+	return optionals.FlatMap[any, T](
+		session.Get(key),
+		func(v any) optionals.Optional[T] {
+			var (
+				obj  T
+				flag bool
+			)
+
+			obj, flag = v.(T)
+			if !flag {
+				return optionals.None[T]()
+			}
+
+			return optionals.Some(obj)
+		})
+}
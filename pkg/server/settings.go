@@ -1,11 +1,52 @@
 package server
 
-import "time"
+import (
+	"runtime"
+	"time"
+
+	"github.com/heartbytenet/go-lerpc/pkg/proto"
+)
+
+type SlowConsumerPolicy int
+
+const (
+	SlowConsumerBlock SlowConsumerPolicy = iota
+	SlowConsumerDrop
+	SlowConsumerDisconnect
+)
 
 type Settings struct {
 	Port          uint16
 	ExecutorLimit int
 	ExecutorDelay time.Duration
+	ExecutorPool  int
+	UploadLimit   int64
+
+	TransferWindow    int
+	TransferChunkSize int
+	StreamBuffer      int
+	EventsBuffer      int
+	EventsReplay      int
+
+	ConnectionBuffer    int
+	SlowConsumerPolicy  SlowConsumerPolicy
+	SlowConsumerTimeout time.Duration
+
+	PingInterval   time.Duration
+	PongTimeout    time.Duration
+	WriteTimeout   time.Duration
+	IdleTimeout    time.Duration
+	MaxMessageSize int64
+
+	CompressionMinSize int
+
+	AllowedOrigins    []string
+	Subprotocols      []string
+	EnableCompression bool
+	ReadBufferSize    int
+	WriteBufferSize   int
+
+	DownloadSecret []byte
 }
 
 func NewSettingsDefault() Settings {
@@ -13,5 +54,39 @@ func NewSettingsDefault() Settings {
 		Port:          3000,
 		ExecutorLimit: 65536,
 		ExecutorDelay: 1,
+		ExecutorPool:  runtime.NumCPU(),
+		UploadLimit:   16 << 20,
+
+		TransferWindow:    16,
+		TransferChunkSize: 64 << 10,
+		StreamBuffer:      64,
+		EventsBuffer:      256,
+		EventsReplay:      1024,
+
+		ConnectionBuffer:    1024,
+		SlowConsumerPolicy:  SlowConsumerBlock,
+		SlowConsumerTimeout: time.Second * 5,
+
+		PingInterval:   time.Second * 30,
+		PongTimeout:    time.Second * 60,
+		WriteTimeout:   time.Second * 10,
+		IdleTimeout:    time.Minute * 10,
+		MaxMessageSize: 4 << 20,
+
+		CompressionMinSize: 1024,
+
+		AllowedOrigins:    nil,
+		Subprotocols:      []string{proto.Subprotocol, proto.SubprotocolMsgpack, proto.SubprotocolCbor, proto.SubprotocolProtobuf, proto.SubprotocolJsonRpc},
+		EnableCompression: false,
+		ReadBufferSize:    4096,
+		WriteBufferSize:   4096,
 	}
 }
+
+func (settings Settings) NewSigner() *Signer {
+	if len(settings.DownloadSecret) == 0 {
+		return NewSignerRandom()
+	}
+
+	return NewSigner(settings.DownloadSecret)
+}
@@ -0,0 +1,124 @@
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrSignatureInvalid   = errors.New("signature invalid")
+	ErrSignatureExpired   = errors.New("signature expired")
+	ErrSignaturePrincipal = errors.New("signature principal mismatch")
+)
+
+const (
+	SignerQueryKey       = "key"
+	SignerQueryExpiry    = "exp"
+	SignerQueryPrincipal = "p"
+	SignerQuerySignature = "sig"
+)
+
+type Signer struct {
+	secret []byte
+}
+
+func NewSigner(secret []byte) *Signer {
+	return &Signer{
+		secret: secret,
+	}
+}
+
+func NewSignerRandom() *Signer {
+	secret := make([]byte, 32)
+
+	_, err := rand.Read(secret)
+	if err != nil {
+		panic(err)
+	}
+
+	return NewSigner(secret)
+}
+
+func NewDownloadKey() (key string, err error) {
+	data := make([]byte, 16)
+
+	_, err = rand.Read(data)
+	if err != nil {
+		return
+	}
+
+	key = hex.EncodeToString(data)
+	return
+}
+
+func (signer *Signer) Signature(key string, expiry int64, principal string, bound bool) string {
+	mac := hmac.New(sha256.New, signer.secret)
+
+	mac.Write([]byte(key))
+	mac.Write([]byte{0})
+	mac.Write([]byte(strconv.FormatInt(expiry, 10)))
+	mac.Write([]byte{0})
+	if bound {
+		mac.Write([]byte{1})
+		mac.Write([]byte(principal))
+	}
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (signer *Signer) Sign(key string, lifetime time.Duration, principal string) (query url.Values) {
+	expiry := time.Now().Add(lifetime).Unix()
+	bound := principal != ""
+
+	query = url.Values{}
+	query.Set(SignerQueryKey, key)
+	query.Set(SignerQueryExpiry, strconv.FormatInt(expiry, 10))
+	if bound {
+		query.Set(SignerQueryPrincipal, "1")
+	}
+	query.Set(SignerQuerySignature, signer.Signature(key, expiry, principal, bound))
+
+	return
+}
+
+func (signer *Signer) Verify(query url.Values, principal string) (key string, err error) {
+	var (
+		expiry int64
+		bound  bool
+	)
+
+	key = query.Get(SignerQueryKey)
+	bound = query.Get(SignerQueryPrincipal) == "1"
+
+	expiry, err = strconv.ParseInt(query.Get(SignerQueryExpiry), 10, 64)
+	if err != nil {
+		err = ErrSignatureInvalid
+		return
+	}
+
+	if !hmac.Equal(
+		[]byte(query.Get(SignerQuerySignature)),
+		[]byte(signer.Signature(key, expiry, principal, bound))) {
+		if bound {
+			err = ErrSignaturePrincipal
+			return
+		}
+
+		err = ErrSignatureInvalid
+		return
+	}
+
+	if time.Now().Unix() > expiry {
+		err = ErrSignatureExpired
+		return
+	}
+
+	return
+}
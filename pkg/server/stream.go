@@ -0,0 +1,121 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"github.com/heartbytenet/go-lerpc/pkg/proto"
+)
+
+var (
+	ErrStreamBufferFull = errors.New("stream receive buffer is full")
+)
+
+type StreamSendFunction func(frame proto.Frame) error
+
+type Stream struct {
+	key      string
+	ctx      context.Context
+	fnSend   StreamSendFunction
+	incoming chan map[string]any
+	window   uint32
+	consumed atomic.Uint32
+	mutex    sync.Mutex
+	closed   bool
+}
+
+func NewStream(ctx context.Context, key string, buffer int, fnSend StreamSendFunction) *Stream {
+	return &Stream{
+		key:      key,
+		ctx:      ctx,
+		fnSend:   fnSend,
+		incoming: make(chan map[string]any, buffer),
+		window:   uint32(buffer),
+	}
+}
+
+func (stream *Stream) GetKey() string {
+	return stream.key
+}
+
+func (stream *Stream) Send(data map[string]any) error {
+	return stream.fnSend(proto.NewFrame(proto.FrameKindStreamData).
+		WithKey(stream.key).
+		WithData(data))
+}
+
+func (stream *Stream) Recv() (data map[string]any, err error) {
+	select {
+	case <-stream.ctx.Done():
+		err = stream.ctx.Err()
+		return
+
+	case value, flag := <-stream.incoming:
+		if !flag {
+			err = io.EOF
+			return
+		}
+
+		data = value
+		stream.consumed.Add(1)
+
+		err = stream.Grant()
+		return
+	}
+}
+
+func (stream *Stream) Grant() error {
+	if stream.window < 1 {
+		return nil
+	}
+
+	return stream.fnSend(proto.NewFrame(proto.FrameKindStreamAck).
+		WithKey(stream.key).
+		WithSeq(stream.consumed.Load() + stream.window))
+}
+
+func (stream *Stream) Push(data map[string]any) (err error) {
+	stream.mutex.Lock()
+	defer stream.mutex.Unlock()
+
+	if stream.closed {
+		return
+	}
+
+	select {
+	case <-stream.ctx.Done():
+		return stream.ctx.Err()
+
+	case stream.incoming <- data:
+		return
+
+	default:
+		return ErrStreamBufferFull
+	}
+}
+
+func (stream *Stream) CloseRecv() {
+	stream.mutex.Lock()
+	defer stream.mutex.Unlock()
+
+	if stream.closed {
+		return
+	}
+
+	stream.closed = true
+	close(stream.incoming)
+}
+
+func (stream *Stream) End(err error) error {
+	if err != nil {
+		return stream.fnSend(proto.NewFrame(proto.FrameKindStreamError).
+			WithKey(stream.key).
+			WithMessage(err.Error()))
+	}
+
+	return stream.fnSend(proto.NewFrame(proto.FrameKindStreamEnd).
+		WithKey(stream.key))
+}
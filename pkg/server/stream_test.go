@@ -0,0 +1,52 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/heartbytenet/go-lerpc/pkg/proto"
+)
+
+func TestStreamGrant(t *testing.T) {
+	var (
+		frames []proto.Frame
+	)
+
+	stream := NewStream(context.Background(), "k", 2, func(frame proto.Frame) error {
+		frames = append(frames, frame)
+		return nil
+	})
+
+	if err := stream.Grant(); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := stream.Push(map[string]any{"i": i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := stream.Push(map[string]any{}); !errors.Is(err, ErrStreamBufferFull) {
+		t.Fatalf("expected full buffer, got %v", err)
+	}
+
+	if _, err := stream.Recv(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(frames) != 2 {
+		t.Fatalf("expected two grants, got %d", len(frames))
+	}
+
+	for i, limit := range []uint32{2, 3} {
+		if frames[i].GetKind() != proto.FrameKindStreamAck || frames[i].GetSeq() != limit {
+			t.Fatalf("grant %d: unexpected frame %+v", i, frames[i])
+		}
+	}
+
+	if err := stream.Push(map[string]any{}); err != nil {
+		t.Fatalf("stream rejected data within its credit: %v", err)
+	}
+}
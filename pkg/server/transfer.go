@@ -0,0 +1,206 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"os"
+
+	"github.com/gorilla/websocket"
+	"github.com/heartbytenet/go-lerpc/pkg/proto"
+)
+
+var (
+	ErrTransferAborted = errors.New("transfer aborted")
+)
+
+type Transfer struct {
+	key    string
+	window uint32
+	acks   chan uint32
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func NewTransfer(parent context.Context, key string, window int) *Transfer {
+	ctx, cancel := context.WithCancel(parent)
+
+	return &Transfer{
+		key:    key,
+		window: uint32(window),
+		acks:   make(chan uint32, window),
+
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+func (transfer *Transfer) GetKey() string {
+	return transfer.key
+}
+
+func (transfer *Transfer) Ack(seq uint32) {
+	select {
+	case transfer.acks <- seq:
+	default:
+	}
+}
+
+func (transfer *Transfer) Abort() {
+	transfer.cancel()
+}
+
+func (transfer *Transfer) Wait(seq uint32, acked uint32) (next uint32, err error) {
+	next = acked
+
+	for seq >= next+transfer.window {
+		select {
+		case <-transfer.ctx.Done():
+			err = ErrTransferAborted
+			return
+
+		case value := <-transfer.acks:
+			if value+1 > next {
+				next = value + 1
+			}
+		}
+	}
+
+	return
+}
+
+func TransferSize(reader io.Reader) int64 {
+	switch value := reader.(type) {
+	case *bytes.Reader:
+		return int64(value.Len())
+
+	case *os.File:
+		info, err := value.Stat()
+		if err != nil {
+			return -1
+		}
+
+		return info.Size()
+
+	default:
+		return -1
+	}
+}
+
+func (server *Server) HandleTransfer(connection *Connection, frame proto.Frame) {
+	var (
+		transfer *Transfer
+		target   *url.URL
+		key      string
+		handler  DownloadHandler
+		reader   io.Reader
+		err      error
+	)
+
+	transfer = NewTransfer(connection.GetContext(), frame.GetKey(), server.settings.TransferWindow)
+
+	connection.AddTransfer(transfer)
+	defer connection.RemoveTransfer(transfer.GetKey())
+	defer transfer.Abort()
+
+	fail := func(err error) {
+		_ = connection.SendFrame(proto.NewFrame(proto.FrameKindTransferError).
+			WithKey(transfer.GetKey()).
+			WithMessage(err.Error()))
+	}
+
+	target, err = url.Parse(frame.GetUrl())
+	if err != nil {
+		fail(err)
+		return
+	}
+
+	key, err = server.executor.GetSigner().Verify(target.Query(), frame.GetToken())
+	if err != nil {
+		fail(err)
+		return
+	}
+
+	handler, err = server.executor.GetDownloadHandlerAlive(key).GetTry()
+	if err != nil {
+		fail(errors.New(ErrorHandlerNotFound))
+		return
+	}
+
+	reader, err = handler.Pull()
+	if err != nil {
+		fail(err)
+		return
+	}
+
+	if closer, flag := reader.(io.Closer); flag {
+		defer closer.Close()
+	}
+
+	err = connection.SendFrame(proto.NewFrame(proto.FrameKindTransferStart).
+		WithKey(transfer.GetKey()).
+		WithSize(TransferSize(reader)).
+		WithMessage(handler.GetContentType()))
+	if err != nil {
+		return
+	}
+
+	err = server.SendTransfer(connection, transfer, reader)
+	if errors.Is(err, ErrTransferAborted) || errors.Is(err, ErrConnectionClosed) {
+		return
+	}
+	if err != nil {
+		fail(err)
+		return
+	}
+}
+
+func (server *Server) SendTransfer(connection *Connection, transfer *Transfer, reader io.Reader) (err error) {
+	var (
+		buffer []byte
+		data   []byte
+		seq    uint32
+		acked  uint32
+		size   int
+	)
+
+	buffer = make([]byte, server.settings.TransferChunkSize)
+
+	for {
+		size, err = io.ReadFull(reader, buffer)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return
+		}
+
+		acked, err = transfer.Wait(seq, acked)
+		if err != nil {
+			return
+		}
+
+		data, err = proto.EncodeChunk(transfer.GetKey(), seq, buffer[:size])
+		if err != nil {
+			return
+		}
+
+		err = connection.Send(websocket.BinaryMessage, data)
+		if err != nil {
+			return
+		}
+
+		seq++
+
+		if size < len(buffer) {
+			break
+		}
+	}
+
+	return connection.SendFrame(proto.NewFrame(proto.FrameKindTransferEnd).
+		WithKey(transfer.GetKey()).
+		WithSeq(seq))
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/gorilla/websocket"
+)
+
+var (
+	ErrorSubprotocolUnsupported = "unsupported websocket subprotocol"
+)
+
+func (settings Settings) NewUpgrader() websocket.Upgrader {
+	upgrader := websocket.Upgrader{
+		ReadBufferSize:    settings.ReadBufferSize,
+		WriteBufferSize:   settings.WriteBufferSize,
+		EnableCompression: settings.EnableCompression,
+	}
+
+	if len(settings.AllowedOrigins) > 0 {
+		upgrader.CheckOrigin = func(request *http.Request) bool {
+			return CheckOrigin(settings.AllowedOrigins, request.Header.Get("Origin"))
+		}
+	}
+
+	return upgrader
+}
+
+func CheckOrigin(allowed []string, origin string) bool {
+	if origin == "" {
+		return true
+	}
+
+	origin = strings.ToLower(origin)
+
+	for _, pattern := range allowed {
+		if pattern == "*" {
+			return true
+		}
+
+		flag, err := path.Match(strings.ToLower(pattern), origin)
+		if err != nil {
+			continue
+		}
+
+		if flag {
+			return true
+		}
+	}
+
+	return false
+}
+
+func CheckSubprotocol(supported []string, request *http.Request) bool {
+	_, flag := SelectSubprotocol(supported, request)
+
+	return flag
+}
+
+func SelectSubprotocol(supported []string, request *http.Request) (value string, flag bool) {
+	requested := websocket.Subprotocols(request)
+	if len(requested) < 1 {
+		return "", true
+	}
+
+	for _, value = range requested {
+		if slices.Contains(supported, value) {
+			return value, true
+		}
+	}
+
+	return "", false
+}